@@ -0,0 +1,370 @@
+// Package aql provides a builder for AQL queries which produces the query string
+// and bind variables to be used with the cursor and modifying query methods of the client.
+// Collections and values are always passed as bind variables so they can't alter
+// the structure of the query.
+//
+//	q := aql.For("u").In("users").
+//		Filter(aql.Gte("u.age", 21)).
+//		Sort(types.Desc("u.age")).
+//		Limit(10).
+//		Return(aql.Var("u"))
+//	params, err := q.CursorQueryParams()
+package aql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/freshwebio/go-microfoxx/types"
+)
+
+// ErrInvalidQuery is the error returned when building a query that contains
+// an invalid variable name, attribute path or clause.
+var ErrInvalidQuery = errors.New("The AQL query is invalid")
+
+// Direction is the direction in which the edges of a graph are followed in a traversal.
+type Direction string
+
+const (
+	// Outbound follows edges from their _from vertex to their _to vertex.
+	Outbound Direction = "OUTBOUND"
+	// Inbound follows edges from their _to vertex to their _from vertex.
+	Inbound Direction = "INBOUND"
+	// Any follows edges in both directions.
+	Any Direction = "ANY"
+)
+
+// Query builds an AQL query clause by clause, each method appends a clause
+// and returns the query so calls can be chained. The first error encountered
+// is kept and returned when the query is built.
+type Query struct {
+	parts       []string
+	bindVars    map[string]interface{}
+	collections map[string]string
+	writeColls  []string
+	values      int
+	// Set after a modification keyword so the next IN or INTO records a write collection.
+	modifying bool
+	err       error
+}
+
+// New creates an empty query.
+func New() *Query {
+	return &Query{
+		bindVars:    make(map[string]interface{}),
+		collections: make(map[string]string),
+	}
+}
+
+// For creates a new query starting with a FOR clause.
+func For(vars ...string) *Query {
+	return New().For(vars...)
+}
+
+// Let creates a new query starting with a LET clause.
+func Let(variable string, expr Expr) *Query {
+	return New().Let(variable, expr)
+}
+
+// Insert creates a new query starting with an INSERT operation.
+func Insert(doc interface{}) *Query {
+	return New().Insert(doc)
+}
+
+// Update creates a new query starting with an UPDATE operation.
+func Update(doc interface{}) *Query {
+	return New().Update(doc)
+}
+
+// Replace creates a new query starting with a REPLACE operation.
+func Replace(doc interface{}) *Query {
+	return New().Replace(doc)
+}
+
+// Remove creates a new query starting with a REMOVE operation.
+func Remove(doc interface{}) *Query {
+	return New().Remove(doc)
+}
+
+// Upsert creates a new query starting with an UPSERT operation.
+func Upsert(search interface{}) *Query {
+	return New().Upsert(search)
+}
+
+// For appends a FOR clause declaring the provided variables, a single variable for
+// iterating over a collection or array and up to three for graph traversals.
+func (q *Query) For(vars ...string) *Query {
+	if len(vars) == 0 || len(vars) > 3 {
+		q.fail(fmt.Errorf("%w: FOR needs between one and three variables", ErrInvalidQuery))
+		return q
+	}
+	for _, v := range vars {
+		q.ident(v)
+	}
+	return q.append("FOR " + strings.Join(vars, ", "))
+}
+
+// In appends IN with the provided collection, following FOR it iterates
+// over the collection and following UPDATE, REPLACE, REMOVE or UPSERT
+// it is the collection written to.
+func (q *Query) In(coll string) *Query {
+	return q.append("IN " + q.collection(coll))
+}
+
+// Into appends INTO with the provided collection the documents of an INSERT are written to.
+func (q *Query) Into(coll string) *Query {
+	return q.append("INTO " + q.collection(coll))
+}
+
+// InExpr appends IN with the provided expression to iterate over an array.
+func (q *Query) InExpr(expr Expr) *Query {
+	return q.append("IN " + q.expr(expr))
+}
+
+// Traverse appends a graph traversal from the start vertex following edges in the
+// provided direction between min and max steps away, it must be followed by
+// Graph or Edges. The start vertex is bound unless it is an expression.
+func (q *Query) Traverse(direction Direction, min int, max int, start interface{}) *Query {
+	if direction != Outbound && direction != Inbound && direction != Any {
+		q.fail(fmt.Errorf("%w: unknown traversal direction %q", ErrInvalidQuery, direction))
+		return q
+	}
+	if min < 0 || max < min {
+		q.fail(fmt.Errorf("%w: invalid traversal depth %d..%d", ErrInvalidQuery, min, max))
+		return q
+	}
+	return q.append("IN " + strconv.Itoa(min) + ".." + strconv.Itoa(max) + " " +
+		string(direction) + " " + q.operand(start))
+}
+
+// Graph appends the named graph a traversal follows the edges of.
+func (q *Query) Graph(name string) *Query {
+	return q.append("GRAPH " + q.bind(name))
+}
+
+// Edges appends the edge collections a traversal follows when it isn't run on a named graph.
+func (q *Query) Edges(colls ...string) *Query {
+	if len(colls) == 0 {
+		q.fail(fmt.Errorf("%w: a traversal needs at least one edge collection", ErrInvalidQuery))
+		return q
+	}
+	bound := make([]string, 0, len(colls))
+	for _, coll := range colls {
+		bound = append(bound, q.collection(coll))
+	}
+	return q.append(strings.Join(bound, ", "))
+}
+
+// Filter appends a FILTER clause with the provided expression.
+func (q *Query) Filter(expr Expr) *Query {
+	return q.append("FILTER " + q.expr(expr))
+}
+
+// Let appends a LET clause assigning the expression to the variable.
+func (q *Query) Let(variable string, expr Expr) *Query {
+	q.ident(variable)
+	return q.append("LET " + variable + " = " + q.expr(expr))
+}
+
+// Sort appends a SORT clause ordering by the provided fields, the field
+// of each sort field is an attribute path starting from a variable.
+func (q *Query) Sort(fields ...*types.SortField) *Query {
+	if len(fields) == 0 {
+		q.fail(fmt.Errorf("%w: SORT needs at least one field", ErrInvalidQuery))
+		return q
+	}
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field == nil {
+			q.fail(fmt.Errorf("%w: nil sort field", ErrInvalidQuery))
+			return q
+		}
+		direction, err := types.ParseSortDirection(string(field.Direction))
+		if err != nil {
+			q.fail(err)
+			return q
+		}
+		parts = append(parts, q.path(field.Field)+" "+string(direction))
+	}
+	return q.append("SORT " + strings.Join(parts, ", "))
+}
+
+// Limit appends a LIMIT clause restricting the number of results.
+func (q *Query) Limit(count int) *Query {
+	return q.LimitOffset(0, count)
+}
+
+// LimitOffset appends a LIMIT clause skipping offset results before providing at most count results.
+func (q *Query) LimitOffset(offset int, count int) *Query {
+	if offset < 0 || count < 0 {
+		q.fail(fmt.Errorf("%w: LIMIT can't be negative", ErrInvalidQuery))
+		return q
+	}
+	if offset == 0 {
+		return q.append("LIMIT " + strconv.Itoa(count))
+	}
+	return q.append("LIMIT " + strconv.Itoa(offset) + ", " + strconv.Itoa(count))
+}
+
+// Insert appends an INSERT operation for the document, which is bound unless it is
+// an expression. It is followed by Into or, as part of an UPSERT, by Update or Replace.
+func (q *Query) Insert(doc interface{}) *Query {
+	return q.modification("INSERT", doc)
+}
+
+// Update appends an UPDATE operation for the document or key, followed by With
+// when only the key is provided and by In with the collection to update.
+func (q *Query) Update(doc interface{}) *Query {
+	return q.modification("UPDATE", doc)
+}
+
+// Replace appends a REPLACE operation for the document or key, followed by With
+// when only the key is provided and by In with the collection to replace in.
+func (q *Query) Replace(doc interface{}) *Query {
+	return q.modification("REPLACE", doc)
+}
+
+// Remove appends a REMOVE operation for the document or key, followed by In
+// with the collection to remove from.
+func (q *Query) Remove(doc interface{}) *Query {
+	return q.modification("REMOVE", doc)
+}
+
+// Upsert appends an UPSERT operation matching documents against the search object,
+// followed by Insert and then Update or Replace.
+func (q *Query) Upsert(search interface{}) *Query {
+	return q.modification("UPSERT", search)
+}
+
+// With appends the changes to apply to the document of an UPDATE or REPLACE.
+func (q *Query) With(doc interface{}) *Query {
+	return q.append("WITH " + q.operand(doc))
+}
+
+// Return appends a RETURN clause with the provided expression.
+func (q *Query) Return(expr Expr) *Query {
+	return q.append("RETURN " + q.expr(expr))
+}
+
+// ReturnDistinct appends a RETURN DISTINCT clause with the provided expression.
+func (q *Query) ReturnDistinct(expr Expr) *Query {
+	return q.append("RETURN DISTINCT " + q.expr(expr))
+}
+
+// Build provides the query string and its bind variables,
+// or the first error encountered while building the query.
+func (q *Query) Build() (string, map[string]interface{}, error) {
+	if q.err != nil {
+		return "", nil, q.err
+	}
+	if len(q.parts) == 0 {
+		return "", nil, fmt.Errorf("%w: the query is empty", ErrInvalidQuery)
+	}
+	bindVars := make(map[string]interface{}, len(q.bindVars))
+	for name, value := range q.bindVars {
+		bindVars[name] = value
+	}
+	return strings.Join(q.parts, " "), bindVars, nil
+}
+
+// WriteCollections provides the collections the query writes to in the order they appear.
+func (q *Query) WriteCollections() []string {
+	return append([]string{}, q.writeColls...)
+}
+
+// CursorQueryParams provides the parameters to run the query with CursorQuery.
+func (q *Query) CursorQueryParams() (*types.CursorQueryParams, error) {
+	query, bindVars, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &types.CursorQueryParams{Query: query, BindVars: bindVars}, nil
+}
+
+// ModifyingQueryParams provides the parameters to run the query with one of the modifying
+// query methods, every collection the query writes to is declared as a write collection.
+func (q *Query) ModifyingQueryParams() (*types.ModifyingQueryParams, error) {
+	query, bindVars, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &types.ModifyingQueryParams{
+		WriteCollections: q.WriteCollections(),
+		Query:            query,
+		BindVars:         bindVars,
+	}, nil
+}
+
+func (q *Query) append(part string) *Query {
+	q.parts = append(q.parts, part)
+	return q
+}
+
+func (q *Query) modification(keyword string, doc interface{}) *Query {
+	q.modifying = true
+	return q.append(keyword + " " + q.operand(doc))
+}
+
+// Deals with keeping the first error encountered while building the query.
+func (q *Query) fail(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+func (q *Query) ident(name string) {
+	if !identRegExp.MatchString(name) {
+		q.fail(fmt.Errorf("%w: invalid variable name %q", ErrInvalidQuery, name))
+	}
+}
+
+func (q *Query) path(path string) string {
+	if !pathRegExp.MatchString(path) {
+		q.fail(fmt.Errorf("%w: invalid attribute path %q", ErrInvalidQuery, path))
+	}
+	return path
+}
+
+func (q *Query) expr(expr Expr) string {
+	if expr == nil {
+		q.fail(fmt.Errorf("%w: nil expression", ErrInvalidQuery))
+		return ""
+	}
+	return expr.build(q)
+}
+
+// Deals with building the value when it's an expression and binding it otherwise.
+func (q *Query) operand(value interface{}) string {
+	if expr, ok := value.(Expr); ok {
+		return q.expr(expr)
+	}
+	return q.bind(value)
+}
+
+func (q *Query) bind(value interface{}) string {
+	name := "v" + strconv.Itoa(q.values)
+	q.values++
+	q.bindVars[name] = value
+	return "@" + name
+}
+
+// Deals with binding the collection, reusing the same bind parameter each time
+// the collection appears and recording it when it follows a modification.
+func (q *Query) collection(coll string) string {
+	if coll == "" {
+		q.fail(fmt.Errorf("%w: empty collection name", ErrInvalidQuery))
+	}
+	name, ok := q.collections[coll]
+	if !ok {
+		name = "c" + strconv.Itoa(len(q.collections))
+		q.collections[coll] = name
+		q.bindVars["@"+name] = coll
+	}
+	if q.modifying {
+		q.modifying = false
+		q.writeColls = append(q.writeColls, coll)
+	}
+	return "@@" + name
+}
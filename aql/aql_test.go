@@ -0,0 +1,105 @@
+package aql_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/freshwebio/go-microfoxx/aql"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type AQLSuite struct{}
+
+var _ = Suite(&AQLSuite{})
+
+func (s *AQLSuite) TestForQuery(c *C) {
+	q := aql.For("u").In("users").
+		Filter(aql.And(aql.Gte("u.age", 21), aql.Or(aql.Eq("u.address.city", "London"), aql.Like("u.name", "J%")))).
+		Sort(types.Desc("u.age"), types.Asc("u.name")).
+		LimitOffset(20, 10).
+		Return(aql.Object(map[string]interface{}{"name": aql.Var("u.name"), "adult": true}))
+	query, bindVars, err := q.Build()
+	c.Assert(err, IsNil)
+	c.Assert(query, Equals, "FOR u IN @@c0 "+
+		"FILTER (u.age >= @v0 AND (u.address.city == @v1 OR u.name LIKE @v2)) "+
+		"SORT u.age DESC, u.name ASC LIMIT 20, 10 RETURN { adult: @v3, name: u.name }")
+	c.Assert(bindVars, DeepEquals, map[string]interface{}{
+		"@c0": "users",
+		"v0":  21,
+		"v1":  "London",
+		"v2":  "J%",
+		"v3":  true,
+	})
+	params, err := q.CursorQueryParams()
+	c.Assert(err, IsNil)
+	c.Assert(params.Query, Equals, query)
+	c.Assert(params.BindVars, DeepEquals, bindVars)
+	c.Assert(q.WriteCollections(), DeepEquals, []string{})
+}
+
+func (s *AQLSuite) TestModifyingQueries(c *C) {
+	q := aql.For("o").InExpr(aql.Value([]string{"a", "b"})).
+		Insert(aql.Object(map[string]interface{}{"item": aql.Var("o")})).Into("orders").
+		Update(aql.Var("o")).With(aql.Object(map[string]interface{}{"reserved": true})).In("inventory")
+	params, err := q.ModifyingQueryParams()
+	c.Assert(err, IsNil)
+	c.Assert(params.Query, Equals, "FOR o IN @v0 INSERT { item: o } INTO @@c0 "+
+		"UPDATE o WITH { reserved: @v1 } IN @@c1")
+	c.Assert(params.WriteCollections, DeepEquals, []string{"orders", "inventory"})
+	c.Assert(params.WriteTargets(), DeepEquals, []string{"orders", "inventory"})
+	c.Assert(params.Validate(), IsNil)
+	// Upserts record the collection once and reuse its bind parameter.
+	q = aql.For("d").In("stock").
+		Upsert(aql.Object(map[string]interface{}{"_key": aql.Var("d._key")})).
+		Insert(aql.Var("d")).
+		Replace(aql.Var("d")).In("stock")
+	query, bindVars, err := q.Build()
+	c.Assert(err, IsNil)
+	c.Assert(query, Equals, "FOR d IN @@c0 UPSERT { _key: d._key } INSERT d REPLACE d IN @@c0")
+	c.Assert(bindVars, DeepEquals, map[string]interface{}{"@c0": "stock"})
+	c.Assert(q.WriteCollections(), DeepEquals, []string{"stock"})
+	query, _, err = aql.Remove("k1").In("stock").Build()
+	c.Assert(err, IsNil)
+	c.Assert(query, Equals, "REMOVE @v0 IN @@c0")
+}
+
+func (s *AQLSuite) TestTraversal(c *C) {
+	query, bindVars, err := aql.For("v", "e").Traverse(aql.Outbound, 1, 2, "users/1").Graph("social").
+		Filter(aql.Not(aql.Eq("e.type", "blocked"))).
+		ReturnDistinct(aql.Var("v._key")).Build()
+	c.Assert(err, IsNil)
+	c.Assert(query, Equals, "FOR v, e IN 1..2 OUTBOUND @v0 GRAPH @v1 "+
+		"FILTER NOT (e.type == @v2) RETURN DISTINCT v._key")
+	c.Assert(bindVars, DeepEquals, map[string]interface{}{"v0": "users/1", "v1": "social", "v2": "blocked"})
+	query, _, err = aql.Let("start", aql.Value("users/1")).
+		For("v").Traverse(aql.Any, 1, 1, aql.Var("start")).Edges("knows", "follows").
+		Return(aql.Var("v")).Build()
+	c.Assert(err, IsNil)
+	c.Assert(query, Equals, "LET start = @v0 FOR v IN 1..1 ANY start @@c0, @@c1 RETURN v")
+}
+
+func (s *AQLSuite) TestInvalidQueries(c *C) {
+	invalid := []*aql.Query{
+		aql.New(),
+		aql.For("u; REMOVE"),
+		aql.For("u").In("users").Filter(aql.Eq("u.name || true", "a")),
+		aql.For("u").In("users").Sort(&types.SortField{Field: "u.age", Direction: "UP"}),
+		aql.For("u").In("users").Limit(-1),
+		aql.For("u").In("users").Filter(aql.And()),
+		aql.For("u").In("users").Filter(nil),
+		aql.For("v").Traverse("SIDEWAYS", 1, 2, "users/1").Graph("social"),
+		aql.For("v").Traverse(aql.Outbound, 2, 1, "users/1").Graph("social"),
+		aql.For("v").Traverse(aql.Outbound, 1, 2, "users/1").Edges(),
+		aql.Insert(aql.Object(map[string]interface{}{"a b": 1})).Into("test"),
+		aql.Insert(aql.Value(1)).Into(""),
+	}
+	for _, q := range invalid {
+		_, _, err := q.Build()
+		c.Assert(errors.Is(err, aql.ErrInvalidQuery) || errors.Is(err, types.ErrInvalidSort), Equals, true)
+		_, err = q.CursorQueryParams()
+		c.Assert(err, NotNil)
+	}
+}
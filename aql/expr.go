@@ -0,0 +1,166 @@
+package aql
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Matches variable names and dot separated attribute paths starting from a variable.
+var pathRegExp = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*(\\.[A-Za-z_][A-Za-z0-9_]*)*$")
+
+// Matches variable names and object attribute names.
+var identRegExp = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
+
+// Expr is an AQL expression used in the clauses of a query. Values in an
+// expression are never written into the query string, they are always passed
+// as bind variables.
+type Expr interface {
+	build(q *Query) string
+}
+
+type varExpr string
+
+type valueExpr struct {
+	value interface{}
+}
+
+type opExpr struct {
+	path  string
+	op    string
+	value interface{}
+}
+
+type logicalExpr struct {
+	op    string
+	exprs []Expr
+}
+
+type notExpr struct {
+	expr Expr
+}
+
+type objectExpr map[string]interface{}
+
+// Var creates an expression referencing a variable or one of its attributes
+// through a dot separated path, such as "u" or "u.address.city".
+func Var(path string) Expr {
+	return varExpr(path)
+}
+
+// Value creates an expression for a value which is passed as a bind variable.
+func Value(value interface{}) Expr {
+	return &valueExpr{value: value}
+}
+
+// Eq creates an expression comparing the attribute path to the value with ==,
+// the value is bound unless it is itself an expression.
+func Eq(path string, value interface{}) Expr {
+	return &opExpr{path: path, op: "==", value: value}
+}
+
+// Ne creates an expression comparing the attribute path to the value with !=.
+func Ne(path string, value interface{}) Expr {
+	return &opExpr{path: path, op: "!=", value: value}
+}
+
+// Lt creates an expression comparing the attribute path to the value with <.
+func Lt(path string, value interface{}) Expr {
+	return &opExpr{path: path, op: "<", value: value}
+}
+
+// Lte creates an expression comparing the attribute path to the value with <=.
+func Lte(path string, value interface{}) Expr {
+	return &opExpr{path: path, op: "<=", value: value}
+}
+
+// Gt creates an expression comparing the attribute path to the value with >.
+func Gt(path string, value interface{}) Expr {
+	return &opExpr{path: path, op: ">", value: value}
+}
+
+// Gte creates an expression comparing the attribute path to the value with >=.
+func Gte(path string, value interface{}) Expr {
+	return &opExpr{path: path, op: ">=", value: value}
+}
+
+// In creates an expression matching when the attribute path equals one of the values in a list.
+func In(path string, values interface{}) Expr {
+	return &opExpr{path: path, op: "IN", value: values}
+}
+
+// Like creates an expression matching the attribute path against an AQL LIKE pattern.
+func Like(path string, pattern interface{}) Expr {
+	return &opExpr{path: path, op: "LIKE", value: pattern}
+}
+
+// And creates an expression matching when all of the provided expressions match.
+func And(exprs ...Expr) Expr {
+	return &logicalExpr{op: "AND", exprs: exprs}
+}
+
+// Or creates an expression matching when at least one of the provided expressions matches.
+func Or(exprs ...Expr) Expr {
+	return &logicalExpr{op: "OR", exprs: exprs}
+}
+
+// Not creates an expression negating the provided expression.
+func Not(expr Expr) Expr {
+	return &notExpr{expr: expr}
+}
+
+// Object creates an object expression from the provided attributes,
+// attribute values are bound unless they are themselves expressions.
+func Object(attrs map[string]interface{}) Expr {
+	return objectExpr(attrs)
+}
+
+func (e varExpr) build(q *Query) string {
+	return q.path(string(e))
+}
+
+func (e *valueExpr) build(q *Query) string {
+	return q.bind(e.value)
+}
+
+func (e *opExpr) build(q *Query) string {
+	return q.path(e.path) + " " + e.op + " " + q.operand(e.value)
+}
+
+func (e *logicalExpr) build(q *Query) string {
+	if len(e.exprs) == 0 {
+		q.fail(fmt.Errorf("%w: %s needs at least one expression", ErrInvalidQuery, e.op))
+		return ""
+	}
+	parts := make([]string, 0, len(e.exprs))
+	for _, expr := range e.exprs {
+		parts = append(parts, q.expr(expr))
+	}
+	return "(" + strings.Join(parts, " "+e.op+" ") + ")"
+}
+
+func (e *notExpr) build(q *Query) string {
+	return "NOT (" + q.expr(e.expr) + ")"
+}
+
+func (e objectExpr) build(q *Query) string {
+	if len(e) == 0 {
+		return "{}"
+	}
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	// Sort the attributes so the same object always produces the same query.
+	sort.Strings(names)
+	attrs := make([]string, 0, len(names))
+	for _, name := range names {
+		if !identRegExp.MatchString(name) {
+			q.fail(fmt.Errorf("%w: invalid attribute name %q", ErrInvalidQuery, name))
+			return ""
+		}
+		attrs = append(attrs, name+": "+q.operand(e[name]))
+	}
+	return "{ " + strings.Join(attrs, ", ") + " }"
+}
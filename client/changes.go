@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/freshwebio/go-microfoxx/types"
+)
+
+const (
+	changesEndpoint = "/changes"
+)
+
+// ChangesClient provides the functionality to subscribe to the events
+// produced for every change to the documents in a collection.
+type ChangesClient interface {
+	Subscribe(string, string) *Subscription
+	SubscribeWithOptions(string, string, *types.SubscribeOptions) *Subscription
+}
+
+// Subscription provides a stream of the events from the change feed of a collection.
+// Events are delivered in order on the Events channel which is closed once the subscription
+// is closed or fails with an error that reconnecting can't recover from. When the session
+// expires the subscription ends with ErrUnauthorized, the client can then be refreshed
+// and the feed resumed by subscribing again from the Checkpoint.
+type Subscription struct {
+	client     *clientImpl
+	coll       string
+	opts       types.SubscribeOptions
+	buffer     chan *feedItem
+	events     chan *types.Event
+	ctx        context.Context
+	cancel     context.CancelFunc
+	done       chan struct{}
+	mu         sync.Mutex
+	checkpoint string
+	err        error
+}
+
+// An item in the buffer of a subscription, items without an event
+// only move the checkpoint forward once every prior event has been received.
+type feedItem struct {
+	evt  *types.Event
+	tick string
+}
+
+// Subscribe deals with subscribing to the change feed of the provided collection with
+// the default options, only events after fromTick are delivered and an empty fromTick
+// starts from the current end of the feed.
+func (c *clientImpl) Subscribe(coll string, fromTick string) *Subscription {
+	return c.SubscribeWithOptions(coll, fromTick, &types.SubscribeOptions{})
+}
+
+// SubscribeWithOptions deals with subscribing to the change feed of the provided collection.
+// The feed is long-polled in the background, polling is paused while the buffer of events is
+// full and failed polls are retried with an exponential backoff from the last checkpoint,
+// so no events are skipped when reconnecting. Nil options are the same as the default options.
+func (c *clientImpl) SubscribeWithOptions(coll string, fromTick string, opts *types.SubscribeOptions) *Subscription {
+	if opts == nil {
+		opts = &types.SubscribeOptions{}
+	}
+	s := &Subscription{
+		client:     c,
+		coll:       coll,
+		opts:       *opts,
+		checkpoint: fromTick,
+		done:       make(chan struct{}),
+	}
+	if s.opts.BufferSize <= 0 {
+		s.opts.BufferSize = 100
+	}
+	if s.opts.PollTimeout <= 0 {
+		s.opts.PollTimeout = 5 * time.Second
+	}
+	if s.opts.MinBackoff <= 0 {
+		s.opts.MinBackoff = 100 * time.Millisecond
+	}
+	if s.opts.MaxBackoff < s.opts.MinBackoff {
+		s.opts.MaxBackoff = 10 * time.Second
+		if s.opts.MaxBackoff < s.opts.MinBackoff {
+			s.opts.MaxBackoff = s.opts.MinBackoff
+		}
+	}
+	s.buffer = make(chan *feedItem, s.opts.BufferSize)
+	s.events = make(chan *types.Event)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	go s.run()
+	go s.forward()
+	return s
+}
+
+// Events provides the channel the events of the subscription are delivered on.
+func (s *Subscription) Events() <-chan *types.Event {
+	return s.events
+}
+
+// Checkpoint provides the tick of the last event received from the Events channel, or the
+// position the feed has been read up to when there haven't been any more events.
+// Passing the checkpoint to a new subscription resumes the feed after that event,
+// to get at-least-once processing store the Tick of each event once it has been processed instead.
+func (s *Subscription) Checkpoint() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpoint
+}
+
+// Err provides the error that ended the subscription, if any.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close stops the subscription and waits for the background polling to finish,
+// any events left in the buffer are discarded.
+func (s *Subscription) Close() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *Subscription) setCheckpoint(tick string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoint = tick
+}
+
+func (s *Subscription) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// Deals with polling the change feed until the subscription is closed
+// or a poll fails in a way reconnecting can't recover from.
+func (s *Subscription) run() {
+	defer close(s.buffer)
+	from := s.Checkpoint()
+	backoff := s.opts.MinBackoff
+	for {
+		events, lastTick, err := s.poll(from)
+		if s.ctx.Err() != nil {
+			return
+		}
+		if err == ErrBadRequest || err == ErrNotFound || err == ErrUnauthorized {
+			// The collection doesn't exist, the tick isn't valid or the session has expired
+			// so trying again won't help.
+			s.setErr(err)
+			return
+		}
+		if err != nil {
+			select {
+			case <-time.After(backoff):
+			case <-s.ctx.Done():
+				return
+			}
+			backoff *= 2
+			if backoff > s.opts.MaxBackoff {
+				backoff = s.opts.MaxBackoff
+			}
+			continue
+		}
+		backoff = s.opts.MinBackoff
+		items := make([]*feedItem, 0, len(events)+1)
+		for _, evt := range events {
+			items = append(items, &feedItem{evt: evt, tick: evt.Tick})
+			from = evt.Tick
+		}
+		if lastTick != "" && lastTick != from {
+			items = append(items, &feedItem{tick: lastTick})
+			from = lastTick
+		}
+		for _, item := range items {
+			// Sending blocks while the buffer is full which pauses polling
+			// until the subscriber catches up.
+			select {
+			case s.buffer <- item:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Deals with handing the buffered events to the subscriber one at a time
+// so the checkpoint only moves forward once an event has been received.
+func (s *Subscription) forward() {
+	defer close(s.done)
+	defer close(s.events)
+	for item := range s.buffer {
+		if item.evt != nil {
+			select {
+			case s.events <- item.evt:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+		s.setCheckpoint(item.tick)
+	}
+}
+
+// Deals with making a single long-poll request for the events after the provided tick.
+func (s *Subscription) poll(from string) ([]*types.Event, string, error) {
+	qParams := make(url.Values)
+	if from != "" {
+		qParams.Add("from", from)
+	}
+	qParams.Add("timeout", strconv.FormatFloat(s.opts.PollTimeout.Seconds(), 'f', -1, 64))
+	req := s.client.prepareRequest("GET", changesEndpoint+"/"+s.coll, qParams, nil).WithContext(s.ctx)
+	resp, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		var intermediary = struct {
+			Events   []*types.Event `json:"events"`
+			LastTick string         `json:"lastTick"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&intermediary)
+		if err != nil {
+			return nil, "", err
+		}
+		return intermediary.Events, intermediary.LastTick, nil
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		// The session is shared with every other request made by the client so it isn't
+		// refreshed from here, the caller refreshes it and resubscribes from the checkpoint.
+		return nil, "", ErrUnauthorized
+	}
+	_, err = prepareExceptionResponse(resp)
+	return nil, "", err
+}
@@ -0,0 +1,265 @@
+package client_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/freshwebio/go-microfoxx/client"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+type ChangesSuite struct {
+	client   Client
+	feed     *changesTestClient
+	fastOpts *types.SubscribeOptions
+}
+
+type changesTestClient struct {
+	dummySessionClient
+	mu       sync.Mutex
+	events   []*types.Event
+	failures int
+	polls    int
+	// The number of polls after which the session expires, if any.
+	expireAfter int
+}
+
+func newChangesTestHttpClient() *changesTestClient {
+	tc := &changesTestClient{}
+	for i := 1; i <= 5; i++ {
+		tc.events = append(tc.events, &types.Event{
+			Tick:       strconv.Itoa(i),
+			Op:         types.EventInsert,
+			Collection: "test",
+			Key:        "k" + strconv.Itoa(i),
+		})
+	}
+	return tc
+}
+
+// Deals with preparing a response for change feed polls, only two events
+// are returned for each poll to ensure the feed is read across several polls.
+func (c *changesTestClient) Do(req *http.Request) (resp *http.Response, err error) {
+	if !strings.HasPrefix(strings.TrimPrefix(req.URL.Path, "/_db//microfoxx"), "/changes/") {
+		// Other requests are answered without a server so nothing synchronises them
+		// with the polls, which lets the race detector catch unguarded shared state.
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("{\"count\":0}")),
+		}, nil
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		c.polls++
+		if c.expireAfter > 0 && c.polls > c.expireAfter {
+			c.mu.Unlock()
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("{\"exception\":\"Error 11: not authorized\"}"))
+			return
+		}
+		if c.failures > 0 {
+			c.failures--
+			c.mu.Unlock()
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("{\"exception\":\"Error 2016: Service unavailable\"}"))
+			return
+		}
+		c.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if strings.TrimPrefix(r.URL.Path, "/_db//microfoxx") != "/changes/test" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("{\"exception\":\"Error 2016: that collection doesn't exist\"}"))
+			return
+		}
+		from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+		c.mu.Lock()
+		batch := make([]*types.Event, 0)
+		for _, evt := range c.events {
+			tick, _ := strconv.Atoi(evt.Tick)
+			if tick > from && len(batch) < 2 {
+				batch = append(batch, evt)
+			}
+		}
+		lastTick := strconv.Itoa(len(c.events))
+		if len(batch) > 0 {
+			lastTick = batch[len(batch)-1].Tick
+		}
+		c.mu.Unlock()
+		if len(batch) == 0 {
+			// Simulate waiting for the poll timeout without any new events.
+			time.Sleep(5 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+		b, _ := json.Marshal(map[string]interface{}{
+			"events":   batch,
+			"lastTick": lastTick,
+		})
+		w.Write(b)
+	}))
+	defer server.Close()
+	newReq, _ := http.NewRequest(req.Method, server.URL+req.URL.Path, req.Body)
+	newReq.URL.RawQuery = req.URL.RawQuery
+	resp, err = http.DefaultClient.Do(newReq)
+	return resp, err
+}
+
+var _ = Suite(&ChangesSuite{})
+
+func (s *ChangesSuite) SetUpTest(c *C) {
+	s.feed = newChangesTestHttpClient()
+	cli, err := NewClient(&types.ConnectionParams{}, s.feed)
+	if err != nil {
+		c.Error("Failed to setup our client for testing.")
+	}
+	s.client = cli
+	s.fastOpts = &types.SubscribeOptions{
+		BufferSize:  1,
+		PollTimeout: 10 * time.Millisecond,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+}
+
+// Deals with receiving the provided amount of events from the subscription
+// failing the test when they don't arrive in time.
+func receiveEvents(c *C, sub *Subscription, count int) []*types.Event {
+	received := make([]*types.Event, 0, count)
+	for len(received) < count {
+		select {
+		case evt, ok := <-sub.Events():
+			if !ok {
+				c.Fatalf("Subscription closed early: %v", sub.Err())
+			}
+			received = append(received, evt)
+		case <-time.After(time.Second):
+			c.Fatalf("Timed out waiting for events")
+		}
+	}
+	return received
+}
+
+func (s *ChangesSuite) TestSubscribe(c *C) {
+	sub := s.client.SubscribeWithOptions("test", "", s.fastOpts)
+	received := receiveEvents(c, sub, 5)
+	for i, evt := range received {
+		c.Assert(evt.Tick, Equals, strconv.Itoa(i+1))
+		c.Assert(evt.Key, Equals, "k"+strconv.Itoa(i+1))
+	}
+	c.Assert(sub.Checkpoint(), Equals, "5")
+	// New events are picked up by later polls.
+	s.feed.mu.Lock()
+	s.feed.events = append(s.feed.events, &types.Event{Tick: "6", Op: types.EventRemove, Key: "k1"})
+	s.feed.mu.Unlock()
+	received = receiveEvents(c, sub, 1)
+	c.Assert(received[0].Op, Equals, types.EventRemove)
+	c.Assert(sub.Checkpoint(), Equals, "6")
+	sub.Close()
+	c.Assert(sub.Err(), Equals, nil)
+	_, open := <-sub.Events()
+	c.Assert(open, Equals, false)
+}
+
+func (s *ChangesSuite) TestSubscribeResume(c *C) {
+	// Resuming from a checkpoint only delivers the events after it.
+	sub := s.client.SubscribeWithOptions("test", "3", s.fastOpts)
+	received := receiveEvents(c, sub, 2)
+	c.Assert(received[0].Tick, Equals, "4")
+	c.Assert(received[1].Tick, Equals, "5")
+	sub.Close()
+}
+
+func (s *ChangesSuite) TestSubscribeNilOptions(c *C) {
+	// Nil options fall back to the defaults rather than panicking.
+	sub := s.client.SubscribeWithOptions("test", "3", nil)
+	received := receiveEvents(c, sub, 2)
+	c.Assert(received[0].Tick, Equals, "4")
+	c.Assert(received[1].Tick, Equals, "5")
+	sub.Close()
+	c.Assert(sub.Err(), Equals, nil)
+}
+
+func (s *ChangesSuite) TestSubscribeBackpressure(c *C) {
+	// Without anyone receiving, polling stops once the buffer is full
+	// and the checkpoint doesn't move past events that weren't received.
+	sub := s.client.SubscribeWithOptions("test", "", s.fastOpts)
+	time.Sleep(50 * time.Millisecond)
+	s.feed.mu.Lock()
+	polls := s.feed.polls
+	s.feed.mu.Unlock()
+	c.Assert(polls <= 2, Equals, true)
+	c.Assert(sub.Checkpoint(), Equals, "")
+	received := receiveEvents(c, sub, 1)
+	c.Assert(received[0].Tick, Equals, "1")
+	sub.Close()
+}
+
+func (s *ChangesSuite) TestSubscribeReconnect(c *C) {
+	// Failed polls are retried without losing any events.
+	s.feed.failures = 3
+	sub := s.client.SubscribeWithOptions("test", "", s.fastOpts)
+	received := receiveEvents(c, sub, 5)
+	c.Assert(received[0].Tick, Equals, "1")
+	c.Assert(received[4].Tick, Equals, "5")
+	sub.Close()
+	c.Assert(sub.Err(), Equals, nil)
+}
+
+func (s *ChangesSuite) TestSubscribeInvalidCollection(c *C) {
+	sub := s.client.SubscribeWithOptions("cars", "", s.fastOpts)
+	select {
+	case _, open := <-sub.Events():
+		c.Assert(open, Equals, false)
+	case <-time.After(time.Second):
+		c.Fatalf("Timed out waiting for the subscription to close")
+	}
+	c.Assert(sub.Err(), Equals, ErrBadRequest)
+	sub.Close()
+}
+
+func (s *ChangesSuite) TestSubscribeSessionExpired(c *C) {
+	// The session expires while other requests are being made with the same client,
+	// the subscription ends without touching the shared session.
+	s.feed.expireAfter = 3
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				s.client.GetDocCount("test", &types.DocumentRetrievalParams{})
+			}
+		}
+	}()
+	sub := s.client.SubscribeWithOptions("test", "", s.fastOpts)
+	received := make([]*types.Event, 0)
+	for evt := range sub.Events() {
+		received = append(received, evt)
+	}
+	close(stop)
+	wg.Wait()
+	c.Assert(sub.Err(), Equals, ErrUnauthorized)
+	c.Assert(len(received), Equals, 5)
+	c.Assert(sub.Checkpoint(), Equals, "5")
+	sub.Close()
+	// Once refreshed the feed resumes from the checkpoint.
+	s.feed.mu.Lock()
+	s.feed.expireAfter = 0
+	s.feed.events = append(s.feed.events, &types.Event{Tick: "6", Op: types.EventRemove, Key: "k1"})
+	s.feed.mu.Unlock()
+	c.Assert(s.client.Refresh(), IsNil)
+	sub = s.client.SubscribeWithOptions("test", sub.Checkpoint(), s.fastOpts)
+	received = receiveEvents(c, sub, 1)
+	c.Assert(received[0].Tick, Equals, "6")
+	sub.Close()
+}
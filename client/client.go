@@ -7,14 +7,18 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
+	"github.com/freshwebio/go-microfoxx/registry"
 	"github.com/freshwebio/go-microfoxx/types"
 )
 
 const (
 	mountEndpoint = "/microfoxx"
 	loginEndpoint = "/login"
+	// The header used to run requests as part of a stream transaction.
+	transactionHeader = "X-Transaction-Id"
 )
 
 var (
@@ -26,6 +30,24 @@ var (
 	// ErrNotFound is the error returned in a response when no results could be found
 	// for a query to the database service.
 	ErrNotFound = errors.New("No results were found for the provided query")
+	// ErrEmptyUpsertSearch is the error returned when an upsert is attempted without
+	// any search attributes to match existing documents against.
+	ErrEmptyUpsertSearch = errors.New("At least one search attribute must be provided for an upsert")
+	// ErrInvalidPageToken is the error returned when a continuation token can't be decoded
+	// or was not produced by this client.
+	ErrInvalidPageToken = errors.New("The provided page token is invalid")
+	// ErrPageOffset is the error returned when a limit offset is provided for keyset pagination
+	// which only supports moving forward from the previous page.
+	ErrPageOffset = errors.New("A limit offset can't be used with keyset pagination")
+	// ErrInvalidTransaction is the error returned when a transaction doesn't provide exactly one
+	// of a sequence of statements or an action, or when one of its statements is empty.
+	ErrInvalidTransaction = errors.New("A transaction needs either a sequence of statements or an action")
+	// ErrInvalidQueryKind is the error returned when a modifying query is made
+	// with a kind other than insert, update, replace, remove or upsert.
+	ErrInvalidQueryKind = errors.New("The kind of modifying query isn't supported")
+	// ErrUnauthorized is the error that ends a subscription when the session of the client
+	// has expired, the session needs to be refreshed before subscribing again.
+	ErrUnauthorized = errors.New("The session of the client has expired")
 )
 
 // Client provides the base definition for all the functionality provided
@@ -35,15 +57,49 @@ type Client interface {
 	GetDocs(coll string, params *types.DocumentRetrievalParams) *types.DocumentsResult
 	CreateDoc(coll string, doc interface{}) *types.DocumentOpResult
 	GetDocCount(coll string, params *types.DocumentRetrievalParams) *types.DocumentCountResult
+	GetDocsPage(coll string, params *types.DocumentRetrievalParams) *types.Page
+	NextPage(token string) *types.Page
 	RemoveDoc(coll string, key string) *types.DocumentOpResult
-	GetDoc(coll string, key string) *types.DocumentResult
+	GetDoc(coll string, key string, fields ...string) *types.DocumentResult
+	DocExists(coll string, key string) *types.DocumentExistsResult
+	DocRevision(coll string, key string) *types.DocumentRevisionResult
+	DocsExist(coll string, keys []string) *types.DocumentsExistResult
+	GetDocsByKeys(coll string, keys []string) *types.DocumentsByKeyResult
 	UpdateDoc(coll string, key string, doc interface{}) *types.DocumentOpResult
+	UpsertDoc(coll string, search map[string]interface{}, insertDoc interface{}, updateDoc interface{}) *types.DocumentUpsertResult
 	CursorQuery(params *types.CursorQueryParams) *types.CursorQueryResult
 	CursorGetNextBatch(cursorID string) *types.CursorQueryResult
+	ExplainQuery(params *types.CursorQueryParams) *types.ExplainResult
+	ProfileQuery(params *types.CursorQueryParams) *types.ProfileResult
+	ParseQuery(query string) *types.ParseResult
+	WithRegistry(reg *registry.Registry) Client
+	Named(name string, bindVars map[string]interface{}) *types.CursorQueryResult
+	NamedModifyingQuery(name string, bindVars map[string]interface{}) *types.DocumentsOpResult
+	Subscribe(coll string, fromTick string) *Subscription
+	SubscribeWithOptions(coll string, fromTick string, opts *types.SubscribeOptions) *Subscription
+	ModifyingQuery(kind types.QueryKind, params *types.ModifyingQueryParams) *types.DocumentsOpResult
 	InsertQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult
 	UpdateQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult
+	ReplaceQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult
 	RemoveQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult
+	UpsertQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult
+	Transaction(params *types.TransactionParams) *types.TransactionResult
+	BeginTransaction(collections *types.TransactionCollections, opts *types.TransactionOptions) (Tx, *types.TransactionStatusResult)
+	WithTransaction(collections *types.TransactionCollections, opts *types.TransactionOptions, fn func(tx Tx) error) error
 	CreateColl(name string) *types.CreationResult
+	CreateCollWithOptions(name string, opts *types.CollectionOptions) *types.CreationResult
+	ListColls() *types.CollectionListResult
+	GetCollProperties(name string) *types.CollectionPropertiesResult
+	UpdateCollProperties(name string, update *types.CollectionPropertiesUpdate) *types.CollectionPropertiesResult
+	GetCollFigures(name string) *types.CollectionFiguresResult
+	DropColl(name string) *types.CollectionOpResult
+	TruncateColl(name string) *types.CollectionOpResult
+	RenameColl(name string, newName string) *types.CollectionOpResult
+	LoadColl(name string) *types.CollectionOpResult
+	UnloadColl(name string) *types.CollectionOpResult
+	SetCollSchema(name string, schema *types.CollectionSchema) *types.CollectionSchemaResult
+	GetCollSchema(name string) *types.CollectionSchemaResult
+	RemoveCollSchema(name string) *types.CollectionSchemaResult
 	CreateGraph(graph *types.Graph) *types.CreationResult
 	CreateRelation(graph string, relation *types.Relation) *types.CreationResult
 	GetIndexes(coll string) *types.IndexListResult
@@ -62,7 +118,33 @@ type clientImpl struct {
 	httpClient       WebClient
 	connectionParams *types.ConnectionParams
 	endpoint         string
-	sessionInfo      *types.SessionInfo
+	session          *session
+	// The stream transaction the requests of the client are part of, if any.
+	txID string
+	// The registry named queries are run from, if any.
+	registry *registry.Registry
+}
+
+// The session shared by a client and the copies made of it for transactions and registries,
+// so refreshing the client refreshes every copy.
+type session struct {
+	mu   sync.RWMutex
+	info *types.SessionInfo
+}
+
+func (s *session) sid() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.info == nil {
+		return ""
+	}
+	return s.info.SID
+}
+
+func (s *session) set(info *types.SessionInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info = info
 }
 
 // NewClient deals with creating a new client setup with the provided connection
@@ -71,7 +153,7 @@ type clientImpl struct {
 // the last time the session was accessed.
 // It is up to the user to initialise a new session by calling a client's Refresh() method.
 func NewClient(cParams *types.ConnectionParams, httpClient ...WebClient) (Client, error) {
-	cli := &clientImpl{}
+	cli := &clientImpl{session: &session{}}
 	// In the case httpClient isn't provided then use standard http.Client with a 10 second timeout.
 	if len(httpClient) > 0 {
 		// Only ever grab the first item as we only care for a single client.
@@ -97,17 +179,18 @@ func NewClient(cParams *types.ConnectionParams, httpClient ...WebClient) (Client
 	// Now deal with setting up the session for the client.
 	sessionInfo, err := cli.newSession()
 	if err == nil {
-		cli.sessionInfo = sessionInfo
+		cli.session.set(sessionInfo)
 	}
 	return cli, err
 }
 
 // Refresh deals with creating a new session and updating the client's current
-// session accordingly.
+// session accordingly, the transactions and registry clients made from the client
+// share its session so they carry on with the new session too.
 func (c *clientImpl) Refresh() error {
 	sessionInfo, err := c.newSession()
 	if err == nil {
-		c.sessionInfo = sessionInfo
+		c.session.set(sessionInfo)
 	}
 	return err
 }
@@ -138,7 +221,10 @@ func (c *clientImpl) prepareRequest(method string, path string, qParams url.Valu
 	if qParams != nil && len(qParams) > 0 {
 		req.URL.RawQuery = qParams.Encode()
 	}
-	req.Header.Add("X-Session-Id", c.sessionInfo.SID)
+	req.Header.Add("X-Session-Id", c.session.sid())
+	if c.txID != "" {
+		req.Header.Add(transactionHeader, c.txID)
+	}
 	return req
 }
 
@@ -154,27 +240,46 @@ func prepareExceptionResponse(resp *http.Response) (message string, err error) {
 		} else if intermediary.ErrMessage != "" {
 			message = intermediary.ErrMessage
 		}
-		switch resp.StatusCode {
-		case http.StatusBadRequest:
-			err = ErrBadRequest
-		case http.StatusNotFound:
-			err = ErrNotFound
-		default:
-			err = ErrGeneral
-		}
+		err = statusError(resp.StatusCode)
 	}
 	return message, err
 }
 
 func prepareExceptionResponseFromMap(statusCode int, respMap map[string]interface{}) (message string, err error) {
 	message = respMap["exception"].(string)
+	return message, statusError(statusCode)
+}
+
+// Provides the error for an unsuccessful response status code,
+// this is used directly for responses without a body such as HEAD requests.
+func statusError(statusCode int) error {
 	switch statusCode {
 	case http.StatusBadRequest:
-		err = ErrBadRequest
+		return ErrBadRequest
 	case http.StatusNotFound:
-		err = ErrNotFound
-	default:
-		err = ErrGeneral
+		return ErrNotFound
 	}
-	return message, err
+	return ErrGeneral
+}
+
+// Deals with decoding the raw JSON of an event into the event model,
+// nil is returned when the event doesn't match the model so the raw
+// event can still be decoded into an application specific type.
+func decodeEvent(data []byte) *types.Event {
+	var evt *types.Event
+	err := json.Unmarshal(data, &evt)
+	if err != nil {
+		return nil
+	}
+	return evt
+}
+
+// Deals with decoding the raw JSON of a list of events into the event model.
+func decodeEvents(data []byte) []*types.Event {
+	var evts []*types.Event
+	err := json.Unmarshal(data, &evts)
+	if err != nil {
+		return nil
+	}
+	return evts
 }
@@ -3,7 +3,9 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/freshwebio/go-microfoxx/types"
 )
@@ -16,15 +18,37 @@ const (
 // collection functionality for the underlying data store service.
 type CollectionClient interface {
 	CreateColl(string) *types.CreationResult
+	CreateCollWithOptions(string, *types.CollectionOptions) *types.CreationResult
+	ListColls() *types.CollectionListResult
+	GetCollProperties(string) *types.CollectionPropertiesResult
+	UpdateCollProperties(string, *types.CollectionPropertiesUpdate) *types.CollectionPropertiesResult
+	GetCollFigures(string) *types.CollectionFiguresResult
+	DropColl(string) *types.CollectionOpResult
+	TruncateColl(string) *types.CollectionOpResult
+	RenameColl(string, string) *types.CollectionOpResult
+	LoadColl(string) *types.CollectionOpResult
+	UnloadColl(string) *types.CollectionOpResult
 }
 
 // CreateColl deals with creating a new collection in the ArangoDB
 // data store with the provided name.
 func (c *clientImpl) CreateColl(name string) *types.CreationResult {
+	return c.CreateCollWithOptions(name, nil)
+}
+
+// CreateCollWithOptions deals with creating a new collection in the ArangoDB data store
+// with the provided name and settings, the options are validated before the request is made.
+func (c *clientImpl) CreateCollWithOptions(name string, opts *types.CollectionOptions) *types.CreationResult {
+	if opts != nil {
+		if err := opts.Validate(); err != nil {
+			return &types.CreationResult{Err: err}
+		}
+	}
 	b := new(bytes.Buffer)
 	err := json.NewEncoder(b).Encode(struct {
 		Name string `json:"name"`
-	}{Name: name})
+		*types.CollectionOptions
+	}{Name: name, CollectionOptions: opts})
 	if err != nil {
 		return &types.CreationResult{Err: err}
 	}
@@ -59,3 +83,124 @@ func (c *clientImpl) CreateColl(name string) *types.CreationResult {
 	}
 	return &creationResult
 }
+
+// ListColls deals with retrieving the information about every collection in the database,
+// including the system collections.
+func (c *clientImpl) ListColls() *types.CollectionListResult {
+	var intermediary = struct {
+		Collections []*types.CollectionInfo `json:"collections"`
+	}{}
+	statusCode, msg, err := c.collectionRequest("GET", createCollEndpoint, nil, &intermediary)
+	return &types.CollectionListResult{
+		Err:         err,
+		StatusCode:  statusCode,
+		Message:     msg,
+		Collections: intermediary.Collections,
+	}
+}
+
+// GetCollProperties deals with retrieving the settings of the collection with the provided name.
+func (c *clientImpl) GetCollProperties(name string) *types.CollectionPropertiesResult {
+	return c.collPropertiesRequest("GET", name, nil)
+}
+
+// UpdateCollProperties deals with changing the settings of the collection with the provided name,
+// the result provides the settings of the collection once they have been changed.
+func (c *clientImpl) UpdateCollProperties(name string, update *types.CollectionPropertiesUpdate) *types.CollectionPropertiesResult {
+	b := new(bytes.Buffer)
+	err := json.NewEncoder(b).Encode(update)
+	if err != nil {
+		return &types.CollectionPropertiesResult{Err: err}
+	}
+	return c.collPropertiesRequest("PUT", name, b)
+}
+
+// GetCollFigures deals with retrieving the number of documents and the storage figures
+// of the collection with the provided name.
+func (c *clientImpl) GetCollFigures(name string) *types.CollectionFiguresResult {
+	var intermediary = struct {
+		Count   int64                    `json:"count"`
+		Figures *types.CollectionFigures `json:"figures"`
+	}{}
+	statusCode, msg, err := c.collectionRequest("GET", collPath(name)+"/figures", nil, &intermediary)
+	return &types.CollectionFiguresResult{
+		Err:        err,
+		StatusCode: statusCode,
+		Message:    msg,
+		Count:      intermediary.Count,
+		Figures:    intermediary.Figures,
+	}
+}
+
+// DropColl deals with removing the collection with the provided name along with
+// all of its documents and indexes.
+func (c *clientImpl) DropColl(name string) *types.CollectionOpResult {
+	return c.collOpRequest("DELETE", collPath(name), nil)
+}
+
+// TruncateColl deals with removing all of the documents from the collection
+// with the provided name while keeping its indexes and settings.
+func (c *clientImpl) TruncateColl(name string) *types.CollectionOpResult {
+	return c.collOpRequest("PUT", collPath(name)+"/truncate", nil)
+}
+
+// RenameColl deals with renaming the collection with the provided name to the new name.
+func (c *clientImpl) RenameColl(name string, newName string) *types.CollectionOpResult {
+	b := new(bytes.Buffer)
+	err := json.NewEncoder(b).Encode(struct {
+		Name string `json:"name"`
+	}{Name: newName})
+	if err != nil {
+		return &types.CollectionOpResult{Err: err}
+	}
+	return c.collOpRequest("PUT", collPath(name)+"/rename", b)
+}
+
+// LoadColl deals with loading the collection with the provided name into memory.
+func (c *clientImpl) LoadColl(name string) *types.CollectionOpResult {
+	return c.collOpRequest("PUT", collPath(name)+"/load", nil)
+}
+
+// UnloadColl deals with unloading the collection with the provided name from memory.
+func (c *clientImpl) UnloadColl(name string) *types.CollectionOpResult {
+	return c.collOpRequest("PUT", collPath(name)+"/unload", nil)
+}
+
+func collPath(name string) string {
+	return createCollEndpoint + "/" + url.PathEscape(name)
+}
+
+func (c *clientImpl) collPropertiesRequest(method string, name string, body io.Reader) *types.CollectionPropertiesResult {
+	var properties types.CollectionProperties
+	statusCode, msg, err := c.collectionRequest(method, collPath(name)+"/properties", body, &properties)
+	propertiesRes := &types.CollectionPropertiesResult{Err: err, StatusCode: statusCode, Message: msg}
+	if err == nil {
+		propertiesRes.Properties = &properties
+	}
+	return propertiesRes
+}
+
+func (c *clientImpl) collOpRequest(method string, path string, body io.Reader) *types.CollectionOpResult {
+	var info types.CollectionInfo
+	statusCode, msg, err := c.collectionRequest(method, path, body, &info)
+	opRes := &types.CollectionOpResult{Err: err, StatusCode: statusCode, Message: msg}
+	if err == nil {
+		opRes.Collection = &info
+	}
+	return opRes
+}
+
+// Deals with making a request for a collection operation and decoding a successful
+// response into out, the message and error are parsed from any other response.
+func (c *clientImpl) collectionRequest(method string, path string, body io.Reader, out interface{}) (int, string, error) {
+	req := c.prepareRequest(method, path, nil, body)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		return resp.StatusCode, "", json.NewDecoder(resp.Body).Decode(out)
+	}
+	msg, err := prepareExceptionResponse(resp)
+	return resp.StatusCode, msg, err
+}
@@ -2,9 +2,13 @@ package client_test
 
 import (
 	"encoding/json"
+	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strconv"
+	"strings"
 	"testing"
 
 	. "github.com/freshwebio/go-microfoxx/client"
@@ -16,41 +20,173 @@ func Test(t *testing.T) { TestingT(t) }
 
 type CollectionSuite struct {
 	client Client
+	tc     *collectionTestClient
 }
 
 type collectionTestClient struct {
 	dummySessionClient
-	collections map[string]string
+	collections map[string]*types.CollectionProperties
+	// The number of documents in each collection.
+	counts   map[string]int64
+	lastBody []byte
 }
 
-// Deals with preparing a response based on whether or not a collection already exists.
+// Deals with preparing a response for collection requests based on
+// the collections that have been created so far.
 func (c *collectionTestClient) Do(req *http.Request) (resp *http.Response, err error) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		collData := struct {
-			Name string `json:"name"`
-		}{}
-		json.NewDecoder(req.Body).Decode(&collData)
-		if _, exists := c.collections[collData.Name]; !exists {
-			nextID := strconv.Itoa(len(c.collections))
-			c.collections[collData.Name] = nextID
-			w.WriteHeader(http.StatusCreated)
-			w.Header().Set("Content-Type", "application/json")
-			w.Write([]byte("{\"message\":\"Successfully created the " + collData.Name + " collection\",\"_id\":\"" +
-				nextID + "\"}"))
-		} else {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Header().Set("Content-Type", "application/json")
-			w.Write([]byte("{\"exception\":\"Error 2016: Collection already exists\"}"))
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(req.URL.Path, "/_db//microfoxx")
+		if path == "/collection" {
+			if req.Method == "GET" {
+				c.list(w)
+			} else {
+				c.create(w, req)
+			}
+			return
+		}
+		parts := strings.SplitN(strings.TrimPrefix(path, "/collection/"), "/", 2)
+		coll, exists := c.collections[parts[0]]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("{\"exception\":\"Error 1203: collection or view not found\"}"))
+			return
 		}
+		action := ""
+		if len(parts) > 1 {
+			action = parts[1]
+		}
+		c.collectionOp(w, req, coll, action)
 	}))
 	defer server.Close()
-	resp, err = http.Post(server.URL, req.Header.Get("Content-Type"), req.Body)
+	newReq, _ := http.NewRequest(req.Method, server.URL+req.URL.Path, req.Body)
+	newReq.Header.Set("Content-Type", req.Header.Get("Content-Type"))
+	resp, err = http.DefaultClient.Do(newReq)
 	return resp, err
 }
 
-func newCollectionTestHttpClient() WebClient {
+func (c *collectionTestClient) create(w http.ResponseWriter, req *http.Request) {
+	c.lastBody, _ = ioutil.ReadAll(req.Body)
+	collData := struct {
+		Name string `json:"name"`
+		types.CollectionOptions
+	}{}
+	json.Unmarshal(c.lastBody, &collData)
+	if collData.Type == 0 {
+		collData.Type = types.CollectionTypeDocument
+	}
+	if _, exists := c.collections[collData.Name]; !exists {
+		nextID := strconv.Itoa(len(c.collections))
+		c.collections[collData.Name] = &types.CollectionProperties{
+			CollectionInfo: types.CollectionInfo{
+				ID:     nextID,
+				Name:   collData.Name,
+				Type:   collData.Type,
+				Status: types.CollectionLoaded,
+			},
+			WaitForSync:       collData.WaitForSync,
+			JournalSize:       33554432,
+			Schema:            collData.Schema,
+			KeyOptions:        collData.KeyOptions,
+			NumberOfShards:    collData.NumberOfShards,
+			ShardKeys:         collData.ShardKeys,
+			ReplicationFactor: collData.ReplicationFactor,
+		}
+		c.counts[collData.Name] = 3
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("{\"message\":\"Successfully created the " + collData.Name + " collection\",\"_id\":\"" +
+			nextID + "\"}"))
+	} else {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("{\"exception\":\"Error 2016: Collection already exists\"}"))
+	}
+}
+
+func (c *collectionTestClient) list(w http.ResponseWriter) {
+	names := make([]string, 0, len(c.collections))
+	for name := range c.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	colls := make([]types.CollectionInfo, 0, len(names))
+	for _, name := range names {
+		colls = append(colls, c.collections[name].CollectionInfo)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"collections": colls})
+}
+
+func (c *collectionTestClient) collectionOp(w http.ResponseWriter, req *http.Request,
+	coll *types.CollectionProperties, action string) {
+	switch {
+	case action == "properties" && req.Method == "PUT":
+		body, _ := ioutil.ReadAll(req.Body)
+		var update types.CollectionPropertiesUpdate
+		json.Unmarshal(body, &update)
+		// The schema is removed when it's provided as null.
+		var fields map[string]json.RawMessage
+		json.Unmarshal(body, &fields)
+		if string(fields["schema"]) == "null" {
+			coll.Schema = nil
+		}
+		if update.JournalSize < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("{\"exception\":\"Error 10: invalid journal size\"}"))
+			return
+		}
+		if update.WaitForSync != nil {
+			coll.WaitForSync = *update.WaitForSync
+		}
+		if update.JournalSize != 0 {
+			coll.JournalSize = update.JournalSize
+		}
+		if update.Schema != nil {
+			coll.Schema = update.Schema
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(coll)
+		return
+	case action == "properties":
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(coll)
+		return
+	case action == "figures":
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{\"count\":" + strconv.FormatInt(c.counts[coll.Name], 10) + ",\"figures\":" +
+			"{\"documentsSize\":1024,\"indexes\":{\"count\":1,\"size\":256},\"cacheInUse\":false}}"))
+		return
+	case action == "" && req.Method == "DELETE":
+		delete(c.collections, coll.Name)
+		coll.Status = types.CollectionDeleted
+	case action == "truncate":
+		c.counts[coll.Name] = 0
+	case action == "rename":
+		var params struct {
+			Name string `json:"name"`
+		}
+		json.NewDecoder(req.Body).Decode(&params)
+		if _, exists := c.collections[params.Name]; exists {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte("{\"exception\":\"Error 1207: duplicate name\"}"))
+			return
+		}
+		delete(c.collections, coll.Name)
+		c.counts[params.Name] = c.counts[coll.Name]
+		coll.Name = params.Name
+		c.collections[coll.Name] = coll
+	case action == "load":
+		coll.Status = types.CollectionLoaded
+	case action == "unload":
+		coll.Status = types.CollectionUnloaded
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(coll.CollectionInfo)
+}
+
+func newCollectionTestHttpClient() *collectionTestClient {
 	tc := &collectionTestClient{}
-	tc.collections = make(map[string]string)
+	tc.collections = make(map[string]*types.CollectionProperties)
+	tc.counts = make(map[string]int64)
 	return tc
 }
 
@@ -60,7 +196,8 @@ func (s *CollectionSuite) SetUpSuite(c *C) {
 	// Simply provide an empty set of connection parameters as our test HTTP client
 	// doesn't care about the url, just the request body for testing the single
 	// collection focused method.
-	cli, err := NewClient(&types.ConnectionParams{}, newCollectionTestHttpClient())
+	s.tc = newCollectionTestHttpClient()
+	cli, err := NewClient(&types.ConnectionParams{}, s.tc)
 	if err != nil {
 		c.Error("Failed to setup our client for testing.")
 	}
@@ -81,3 +218,132 @@ func (s *CollectionSuite) TestCreateColl(c *C) {
 	c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
 	c.Assert(res.Message, Equals, "Error 2016: Collection already exists")
 }
+
+func (s *CollectionSuite) TestCollectionLifecycle(c *C) {
+	c.Assert(s.client.CreateColl("orders").Err, IsNil)
+	c.Assert(s.client.CreateColl("customers").Err, IsNil)
+	listRes := s.client.ListColls()
+	c.Assert(listRes.Err, IsNil)
+	c.Assert(listRes.StatusCode, Equals, http.StatusOK)
+	names := make([]string, 0)
+	for _, coll := range listRes.Collections {
+		names = append(names, coll.Name)
+	}
+	c.Assert(names[:2], DeepEquals, []string{"customers", "orders"})
+	c.Assert(listRes.Collections[1].Type, Equals, types.CollectionTypeDocument)
+	// Properties can be retrieved and updated.
+	propsRes := s.client.GetCollProperties("orders")
+	c.Assert(propsRes.Err, IsNil)
+	c.Assert(propsRes.Properties.Name, Equals, "orders")
+	c.Assert(propsRes.Properties.WaitForSync, Equals, false)
+	c.Assert(propsRes.Properties.Schema, IsNil)
+	waitForSync := true
+	propsRes = s.client.UpdateCollProperties("orders", &types.CollectionPropertiesUpdate{WaitForSync: &waitForSync})
+	c.Assert(propsRes.Err, IsNil)
+	c.Assert(propsRes.Properties.WaitForSync, Equals, true)
+	c.Assert(propsRes.Properties.JournalSize, Equals, int64(33554432))
+	propsRes = s.client.UpdateCollProperties("orders", &types.CollectionPropertiesUpdate{JournalSize: -1})
+	c.Assert(propsRes.Err, Equals, ErrBadRequest)
+	c.Assert(propsRes.Message, Equals, "Error 10: invalid journal size")
+	c.Assert(propsRes.Properties, IsNil)
+	// Figures, truncating, loading and unloading.
+	figuresRes := s.client.GetCollFigures("orders")
+	c.Assert(figuresRes.Err, IsNil)
+	c.Assert(figuresRes.Count, Equals, int64(3))
+	c.Assert(figuresRes.Figures.DocumentsSize, Equals, int64(1024))
+	c.Assert(figuresRes.Figures.Indexes.Count, Equals, int64(1))
+	c.Assert(s.client.TruncateColl("orders").Err, IsNil)
+	c.Assert(s.client.GetCollFigures("orders").Count, Equals, int64(0))
+	opRes := s.client.UnloadColl("orders")
+	c.Assert(opRes.Err, IsNil)
+	c.Assert(opRes.Collection.Status, Equals, types.CollectionUnloaded)
+	c.Assert(s.client.LoadColl("orders").Collection.Status, Equals, types.CollectionLoaded)
+	// Renaming and dropping.
+	opRes = s.client.RenameColl("orders", "customers")
+	c.Assert(opRes.StatusCode, Equals, http.StatusConflict)
+	c.Assert(opRes.Message, Equals, "Error 1207: duplicate name")
+	opRes = s.client.RenameColl("orders", "purchases")
+	c.Assert(opRes.Err, IsNil)
+	c.Assert(opRes.Collection.Name, Equals, "purchases")
+	c.Assert(s.client.GetCollProperties("orders").Err, Equals, ErrNotFound)
+	opRes = s.client.DropColl("purchases")
+	c.Assert(opRes.Err, IsNil)
+	c.Assert(opRes.Collection.Status, Equals, types.CollectionDeleted)
+	opRes = s.client.DropColl("purchases")
+	c.Assert(opRes.Err, Equals, ErrNotFound)
+	c.Assert(opRes.Message, Equals, "Error 1203: collection or view not found")
+	c.Assert(opRes.Collection, IsNil)
+}
+
+func (s *CollectionSuite) TestCreateCollWithOptions(c *C) {
+	allowUserKeys := false
+	res := s.client.CreateCollWithOptions("follows", &types.CollectionOptions{
+		Type: types.CollectionTypeEdge,
+		KeyOptions: &types.CollectionKeyOptions{
+			Type:          types.KeyGeneratorAutoIncrement,
+			AllowUserKeys: &allowUserKeys,
+			Increment:     5,
+			Offset:        100,
+		},
+		NumberOfShards:    1,
+		ReplicationFactor: 2,
+	})
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusCreated)
+	c.Assert(string(s.tc.lastBody), Equals, "{\"name\":\"follows\",\"type\":3,\"keyOptions\":"+
+		"{\"type\":\"autoincrement\",\"allowUserKeys\":false,\"increment\":5,\"offset\":100},"+
+		"\"numberOfShards\":1,\"replicationFactor\":2}\n")
+	propsRes := s.client.GetCollProperties("follows")
+	c.Assert(propsRes.Err, IsNil)
+	c.Assert(propsRes.Properties.Type, Equals, types.CollectionTypeEdge)
+	c.Assert(propsRes.Properties.KeyOptions.Type, Equals, types.KeyGeneratorAutoIncrement)
+	c.Assert(*propsRes.Properties.KeyOptions.AllowUserKeys, Equals, false)
+	c.Assert(propsRes.Properties.ReplicationFactor, Equals, 2)
+	// Collections created without options only send their name.
+	c.Assert(s.client.CreateColl("plain").Err, IsNil)
+	c.Assert(string(s.tc.lastBody), Equals, "{\"name\":\"plain\"}\n")
+	res = s.client.CreateCollWithOptions("sharded", &types.CollectionOptions{
+		KeyOptions:     &types.CollectionKeyOptions{Type: types.KeyGeneratorUUID},
+		NumberOfShards: 3,
+		ShardKeys:      []string{"region", "_key"},
+		Schema: &types.CollectionSchema{
+			Rule:  json.RawMessage("{\"type\":\"object\"}"),
+			Level: types.SchemaLevelModerate,
+		},
+	})
+	c.Assert(res.Err, IsNil)
+	propsRes = s.client.GetCollProperties("sharded")
+	c.Assert(propsRes.Properties.ShardKeys, DeepEquals, []string{"region", "_key"})
+	c.Assert(propsRes.Properties.Schema.Level, Equals, types.SchemaLevelModerate)
+}
+
+func (s *CollectionSuite) TestCreateCollWithInvalidOptions(c *C) {
+	allowUserKeys := true
+	invalid := []*types.CollectionOptions{
+		{Type: 4},
+		{NumberOfShards: -1},
+		{ShardKeys: []string{"region", "region"}},
+		{ShardKeys: []string{""}},
+		{KeyOptions: &types.CollectionKeyOptions{Type: "sequential"}},
+		{KeyOptions: &types.CollectionKeyOptions{Type: types.KeyGeneratorPadded, Increment: 2}},
+		{KeyOptions: &types.CollectionKeyOptions{Type: types.KeyGeneratorAutoIncrement, Offset: -1}},
+		{KeyOptions: &types.CollectionKeyOptions{Type: types.KeyGeneratorAutoIncrement}, NumberOfShards: 2},
+		{KeyOptions: &types.CollectionKeyOptions{AllowUserKeys: &allowUserKeys}, ShardKeys: []string{"region"}},
+		{Schema: &types.CollectionSchema{Level: "strict"}},
+	}
+	s.tc.lastBody = nil
+	for _, opts := range invalid {
+		res := s.client.CreateCollWithOptions("invalid", opts)
+		c.Assert(errors.Is(res.Err, types.ErrInvalidCollectionOptions), Equals, true)
+		c.Assert(res.StatusCode, Equals, 0)
+	}
+	// None of the invalid collections reach the service.
+	c.Assert(s.tc.lastBody, IsNil)
+	c.Assert(s.client.GetCollProperties("invalid").Err, Equals, ErrNotFound)
+	// User keys can be allowed when the documents are only sharded by their keys.
+	res := s.client.CreateCollWithOptions("keyed", &types.CollectionOptions{
+		KeyOptions: &types.CollectionKeyOptions{AllowUserKeys: &allowUserKeys},
+		ShardKeys:  []string{"_key"},
+	})
+	c.Assert(res.Err, IsNil)
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/freshwebio/go-microfoxx/types"
@@ -20,13 +21,35 @@ type CursorClient interface {
 	CursorGetNextBatch(string) *types.CursorQueryResult
 }
 
+// The extra section of a cursor response holding the statistics
+// and warnings of the query.
+type cursorExtra struct {
+	Stats    *types.CursorQueryStats `json:"stats"`
+	Warnings []*types.QueryWarning   `json:"warnings"`
+}
+
+// Deals with setting the statistics and warnings on the result
+// when the response provided them.
+func (e *cursorExtra) apply(res *types.CursorQueryResult) {
+	if e == nil {
+		return
+	}
+	res.Stats = e.Stats
+	res.Warnings = e.Warnings
+}
+
 // CursorQuery sends an AQL query to the ArangoDB service
 // to create a new cursor and return the set of results.
 // You can specify count if you want to retrieve the total amount
 // of results and can supply a batch size to retrieve results in batches.
+// The bind variables must provide exactly the bind parameters used in the query.
 func (c *clientImpl) CursorQuery(params *types.CursorQueryParams) *types.CursorQueryResult {
+	err := params.Validate()
+	if err != nil {
+		return &types.CursorQueryResult{Err: err}
+	}
 	b := new(bytes.Buffer)
-	err := json.NewEncoder(b).Encode(params)
+	err = json.NewEncoder(b).Encode(params)
 	if err != nil {
 		return &types.CursorQueryResult{Err: err}
 	}
@@ -40,10 +63,12 @@ func (c *clientImpl) CursorQuery(params *types.CursorQueryParams) *types.CursorQ
 	cursorQueryRes.StatusCode = resp.StatusCode
 	if cursorQueryRes.StatusCode == http.StatusOK || cursorQueryRes.StatusCode == http.StatusCreated {
 		intermediary := struct {
-			Results []map[string]interface{} `json:"results"`
-			Cursor  string                   `json:"cursor"`
-			HasMore bool                     `json:"hasMore"`
-			Count   int                      `json:"count"`
+			Results []json.RawMessage `json:"results"`
+			Cursor  string            `json:"cursor"`
+			HasMore bool              `json:"hasMore"`
+			Count   int               `json:"count"`
+			Cached  bool              `json:"cached"`
+			Extra   *cursorExtra      `json:"extra"`
 		}{}
 		err := json.NewDecoder(resp.Body).Decode(&intermediary)
 		if err != nil {
@@ -51,6 +76,9 @@ func (c *clientImpl) CursorQuery(params *types.CursorQueryParams) *types.CursorQ
 		}
 		cursorQueryRes.Cursor = intermediary.Cursor
 		cursorQueryRes.HasMore = intermediary.HasMore
+		cursorQueryRes.Count = intermediary.Count
+		cursorQueryRes.Cached = intermediary.Cached
+		intermediary.Extra.apply(&cursorQueryRes)
 		docBytes := new(bytes.Buffer)
 		err = json.NewEncoder(docBytes).Encode(intermediary.Results)
 		if err != nil {
@@ -78,14 +106,16 @@ func (c *clientImpl) CursorGetNextBatch(cursorID string) *types.CursorQueryResul
 	cursorQueryRes.StatusCode = resp.StatusCode
 	if cursorQueryRes.StatusCode == http.StatusOK || cursorQueryRes.StatusCode == http.StatusCreated {
 		intermediary := struct {
-			Results []map[string]interface{} `json:"results"`
-			HasMore bool                     `json:"hasMore"`
+			Results []json.RawMessage `json:"results"`
+			HasMore bool              `json:"hasMore"`
+			Extra   *cursorExtra      `json:"extra"`
 		}{}
 		err := json.NewDecoder(resp.Body).Decode(&intermediary)
 		if err != nil {
 			return &types.CursorQueryResult{Err: err}
 		}
 		cursorQueryRes.HasMore = intermediary.HasMore
+		intermediary.Extra.apply(&cursorQueryRes)
 		docBytes := new(bytes.Buffer)
 		err = json.NewEncoder(docBytes).Encode(intermediary.Results)
 		if err != nil {
@@ -99,3 +129,89 @@ func (c *clientImpl) CursorGetNextBatch(cursorID string) *types.CursorQueryResul
 	}
 	return &cursorQueryRes
 }
+
+// Deals with running a cursor query and retrieving every remaining batch
+// of results, when any of the requests fail the failed result is returned.
+func (c *clientImpl) cursorQueryAll(params *types.CursorQueryParams) ([]map[string]interface{}, *types.CursorQueryResult) {
+	results := make([]map[string]interface{}, 0)
+	res := c.CursorQuery(params)
+	for {
+		if res.Err != nil {
+			return nil, res
+		}
+		var batch []map[string]interface{}
+		err := json.NewDecoder(res.Documents).Decode(&batch)
+		if err != nil {
+			return nil, &types.CursorQueryResult{Err: err}
+		}
+		results = append(results, batch...)
+		if !res.HasMore {
+			return results, nil
+		}
+		cursorID := res.Cursor
+		res = c.CursorGetNextBatch(cursorID)
+		// The cursor identifier is only provided when the cursor is created.
+		res.Cursor = cursorID
+	}
+}
+
+// QueryAll deals with running the cursor query and decoding the results of every batch
+// into a slice of T, which can be a struct, a map or a scalar for queries returning counts
+// or strings. The message of a failed request is included in the returned error.
+func QueryAll[T any](cli CursorClient, params *types.CursorQueryParams) ([]T, error) {
+	results := make([]T, 0)
+	res := cli.CursorQuery(params)
+	cursorID := res.Cursor
+	for {
+		batch, err := decodeBatch[T](res)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batch...)
+		if !res.HasMore {
+			return results, nil
+		}
+		res = cli.CursorGetNextBatch(cursorID)
+	}
+}
+
+// QueryOne deals with running the cursor query and decoding the first result into T,
+// ErrNotFound is returned when the query has no results. The query is run with a batch
+// size of 1 on a copy of the params so no more than the first result is retrieved, the
+// cursor is left to expire on the server when the query has more results.
+func QueryOne[T any](cli CursorClient, params *types.CursorQueryParams) (T, error) {
+	var result T
+	oneParams := *params
+	oneParams.BatchSize = 1
+	res := cli.CursorQuery(&oneParams)
+	cursorID := res.Cursor
+	for {
+		batch, err := decodeBatch[T](res)
+		if err != nil {
+			return result, err
+		}
+		if len(batch) > 0 {
+			return batch[0], nil
+		}
+		if !res.HasMore {
+			return result, ErrNotFound
+		}
+		res = cli.CursorGetNextBatch(cursorID)
+	}
+}
+
+// Deals with decoding a batch of cursor results into a slice of T.
+func decodeBatch[T any](res *types.CursorQueryResult) ([]T, error) {
+	if res.Err != nil {
+		if res.Message != "" {
+			return nil, fmt.Errorf("%w: %s", res.Err, res.Message)
+		}
+		return nil, res.Err
+	}
+	var batch []T
+	err := json.NewDecoder(res.Documents).Decode(&batch)
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
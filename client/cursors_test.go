@@ -3,6 +3,7 @@ package client_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"regexp"
@@ -22,6 +23,7 @@ type testCursor struct {
 	Documents []map[string]interface{}
 	BatchSize int
 	Current   int
+	Extra     map[string]interface{}
 }
 
 type cursorsTestClient struct {
@@ -96,7 +98,16 @@ func (c *cursorsTestClient) newCursor(w http.ResponseWriter, req *http.Request)
 				val = status.(string)
 			}
 			results := c.getDocsWithPropertyVal(property, val)
+			// For the purpose of testing queries on the status raise a warning.
+			if property == "status" && cursorParams.FailOnWarning {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte("{\"exception\":\"Error 1562: division by zero\"}"))
+				return
+			}
+			extra := c.extra(cursorParams, property, len(results))
 			cursor := testCursor{}
+			cursor.Extra = extra
 			cursor.Documents = results
 			// Only create a new cursor where the batch size is set to something other than 0.
 			var respMap map[string]interface{}
@@ -115,6 +126,7 @@ func (c *cursorsTestClient) newCursor(w http.ResponseWriter, req *http.Request)
 				respMap["results"] = batch
 				respMap["hasMore"] = true
 				respMap["cursor"] = nextID
+				respMap["extra"] = extra
 				if cursorParams.Count {
 					respMap["count"] = len(cursor.Documents)
 				}
@@ -123,6 +135,7 @@ func (c *cursorsTestClient) newCursor(w http.ResponseWriter, req *http.Request)
 				respMap = make(map[string]interface{})
 				respMap["results"] = cursor.Documents
 				respMap["hasMore"] = false
+				respMap["extra"] = extra
 				if cursorParams.Count {
 					respMap["count"] = len(cursor.Documents)
 				}
@@ -145,6 +158,26 @@ func (c *cursorsTestClient) newCursor(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+// Deals with preparing the statistics and warnings of a query
+// that scans every document and filters on the provided property.
+func (c *cursorsTestClient) extra(params types.CursorQueryParams, property string, results int) map[string]interface{} {
+	stats := map[string]interface{}{
+		"writesExecuted":  0,
+		"scannedFull":     len(c.documents),
+		"filtered":        len(c.documents) - results,
+		"executionTime":   0.0021,
+		"peakMemoryUsage": 32768,
+	}
+	if params.FullCount {
+		stats["fullCount"] = results
+	}
+	warnings := []map[string]interface{}{}
+	if property == "status" {
+		warnings = append(warnings, map[string]interface{}{"code": 1562, "message": "division by zero"})
+	}
+	return map[string]interface{}{"stats": stats, "warnings": warnings}
+}
+
 func (c *cursorsTestClient) nextBatch(w http.ResponseWriter, req *http.Request, cursorID string) {
 	// First of all we need to attempt to retrieve the cursor with the provided ID.
 	if cursor, exists := c.cursors[cursorID]; exists {
@@ -169,10 +202,15 @@ func (c *cursorsTestClient) nextBatch(w http.ResponseWriter, req *http.Request,
 		dataToSend := struct {
 			HasMore bool                     `json:"hasMore"`
 			Results []map[string]interface{} `json:"results"`
+			Extra   map[string]interface{}   `json:"extra,omitempty"`
 		}{
 			HasMore: hasMore,
 			Results: batch,
 		}
+		// The statistics are provided again with the last batch.
+		if !hasMore {
+			dataToSend.Extra = cursor.Extra
+		}
 		b := new(bytes.Buffer)
 		json.NewEncoder(b).Encode(dataToSend)
 		w.Write(b.Bytes())
@@ -228,13 +266,21 @@ func (s *CursorsSuite) TestCursorQuery(c *C) {
 	c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
 	c.Assert(res.Message, Equals, "Error 2016: Invalid AQL query")
 	// Ensure that a query with bind parameters that do not exist gets an invalid response.
+	// This is now caught before the request is made.
 	res = s.client.CursorQuery(&types.CursorQueryParams{
 		Query:    "FOR item in @@coll FILTER item.name == @name",
 		BindVars: map[string]interface{}{},
 	})
-	c.Assert(res.Err, Not(Equals), nil)
-	c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
-	c.Assert(res.Message, Equals, "Error 2016: Bind parameters in query need to be provided")
+	c.Assert(errors.Is(res.Err, types.ErrBindVars), Equals, true)
+	c.Assert(res.Err.Error(), Equals, "The bind variables don't match the bind parameters of the query: missing @coll, name")
+	c.Assert(res.StatusCode, Equals, 0)
+	// As are bind variables the query doesn't use.
+	res = s.client.CursorQuery(&types.CursorQueryParams{
+		Query:    "FOR item in @@coll FILTER item.name == 'a@b.c' /* @status */ RETURN item",
+		BindVars: map[string]interface{}{"@coll": "users", "name": "testname1", "b": "x"},
+	})
+	c.Assert(errors.Is(res.Err, types.ErrBindVars), Equals, true)
+	c.Assert(res.Err.Error(), Equals, "The bind variables don't match the bind parameters of the query: unused b, name")
 }
 
 func (s *CursorsSuite) TestCursorGetNextBatch(c *C) {
@@ -279,3 +325,174 @@ func (s *CursorsSuite) TestCursorGetNextBatch(c *C) {
 	c.Assert(batchRes.HasMore, Equals, false)
 	c.Assert(batchRes.StatusCode, Equals, http.StatusBadRequest)
 }
+
+func (s *CursorsSuite) TestCursorQueryStats(c *C) {
+	params := &types.CursorQueryParams{
+		Query: "FOR item in @@coll FILTER item.status == @status",
+		BindVars: map[string]interface{}{
+			"@coll":  "users",
+			"status": "enabled",
+		},
+		BatchSize:   20,
+		Count:       true,
+		FullCount:   true,
+		MaxRuntime:  2.5,
+		MemoryLimit: 1 << 20,
+		Cache:       true,
+	}
+	res := s.client.CursorQuery(params)
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.Count, Equals, 25)
+	c.Assert(res.Cached, Equals, false)
+	c.Assert(*res.Stats, Equals, types.CursorQueryStats{
+		ScannedFull:     50,
+		Filtered:        25,
+		FullCount:       25,
+		ExecutionTime:   0.0021,
+		PeakMemoryUsage: 32768,
+	})
+	c.Assert(res.Warnings, DeepEquals, []*types.QueryWarning{{Code: 1562, Message: "division by zero"}})
+	// The last batch provides the statistics again while the other batches don't.
+	batchRes := s.client.CursorGetNextBatch(res.Cursor)
+	for batchRes.Err == nil && batchRes.HasMore {
+		c.Assert(batchRes.Stats, IsNil)
+		batchRes = s.client.CursorGetNextBatch(res.Cursor)
+	}
+	c.Assert(batchRes.Err, IsNil)
+	c.Assert(batchRes.Stats.Filtered, Equals, int64(25))
+	c.Assert(len(batchRes.Warnings), Equals, 1)
+	// Warnings fail the query when requested.
+	params.FailOnWarning = true
+	res = s.client.CursorQuery(params)
+	c.Assert(res.Err, Equals, ErrBadRequest)
+	c.Assert(res.Message, Equals, "Error 1562: division by zero")
+	c.Assert(res.Stats, IsNil)
+	// Queries without a full count leave it unset.
+	res = s.client.CursorQuery(&types.CursorQueryParams{
+		Query:    "FOR item in @@coll FILTER item.name == @name",
+		BindVars: map[string]interface{}{"@coll": "users", "name": "testname1"},
+	})
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.Stats.FullCount, Equals, int64(0))
+	c.Assert(res.Warnings, DeepEquals, []*types.QueryWarning{})
+}
+
+type TypedCursorsSuite struct {
+	client Client
+	tc     *typedCursorsTestClient
+}
+
+type typedCursorsTestClient struct {
+	dummySessionClient
+	// The batches of results for each query, keyed by query.
+	batches map[string][][]interface{}
+	// The batches left to retrieve for each cursor.
+	cursors map[string][][]interface{}
+	// The batch size of the last query.
+	batchSize int
+}
+
+// Deals with serving the batches of results set up for each query.
+func (c *typedCursorsTestClient) Do(req *http.Request) (resp *http.Response, err error) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/_db//microfoxx")
+		var batches [][]interface{}
+		cursorID := strings.TrimPrefix(path, "/cursor/")
+		if path == "/cursor" {
+			var params types.CursorQueryParams
+			json.NewDecoder(r.Body).Decode(&params)
+			c.batchSize = params.BatchSize
+			var ok bool
+			batches, ok = c.batches[params.Query]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("{\"exception\":\"Error 1501: syntax error\"}"))
+				return
+			}
+			cursorID = strconv.Itoa(len(c.cursors) + 1)
+		} else {
+			batches = c.cursors[cursorID]
+		}
+		c.cursors[cursorID] = batches[1:]
+		w.WriteHeader(http.StatusCreated)
+		b, _ := json.Marshal(map[string]interface{}{
+			"results": batches[0],
+			"hasMore": len(batches) > 1,
+			"cursor":  cursorID,
+		})
+		w.Write(b)
+	}))
+	defer server.Close()
+	newReq, _ := http.NewRequest(req.Method, server.URL+req.URL.Path, req.Body)
+	resp, err = http.DefaultClient.Do(newReq)
+	return resp, err
+}
+
+var _ = Suite(&TypedCursorsSuite{})
+
+func (s *TypedCursorsSuite) SetUpTest(c *C) {
+	s.tc = &typedCursorsTestClient{
+		batches: map[string][][]interface{}{
+			"FOR u IN users RETURN u": {
+				{map[string]interface{}{"_key": "u1", "age": 30}, map[string]interface{}{"_key": "u2", "age": 41}},
+				{map[string]interface{}{"_key": "u3", "age": 25}},
+			},
+			"FOR u IN users RETURN u.name":               {{"Ann", "Bob"}, {"Cat"}},
+			"RETURN LENGTH(users)":                       {{9007199254740993}},
+			"FOR u IN users FILTER u.age > 100 RETURN u": {{}, {}},
+		},
+		cursors: make(map[string][][]interface{}),
+	}
+	cli, err := NewClient(&types.ConnectionParams{}, s.tc)
+	if err != nil {
+		c.Error("Failed to setup our client for testing.")
+	}
+	s.client = cli
+}
+
+type typedUser struct {
+	Key string `json:"_key"`
+	Age int    `json:"age"`
+}
+
+func (s *TypedCursorsSuite) TestQueryAll(c *C) {
+	users, err := QueryAll[typedUser](s.client, &types.CursorQueryParams{Query: "FOR u IN users RETURN u"})
+	c.Assert(err, IsNil)
+	c.Assert(users, DeepEquals, []typedUser{{"u1", 30}, {"u2", 41}, {"u3", 25}})
+	names, err := QueryAll[string](s.client, &types.CursorQueryParams{Query: "FOR u IN users RETURN u.name"})
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{"Ann", "Bob", "Cat"})
+	// Large integers are decoded without losing precision.
+	counts, err := QueryAll[int64](s.client, &types.CursorQueryParams{Query: "RETURN LENGTH(users)"})
+	c.Assert(err, IsNil)
+	c.Assert(counts, DeepEquals, []int64{9007199254740993})
+	none, err := QueryAll[typedUser](s.client, &types.CursorQueryParams{Query: "FOR u IN users FILTER u.age > 100 RETURN u"})
+	c.Assert(err, IsNil)
+	c.Assert(none, DeepEquals, []typedUser{})
+	// Results that don't match the type fail to decode.
+	_, err = QueryAll[int](s.client, &types.CursorQueryParams{Query: "FOR u IN users RETURN u.name"})
+	c.Assert(err, NotNil)
+	_, err = QueryAll[string](s.client, &types.CursorQueryParams{Query: "FOR u IN user RETURN u"})
+	c.Assert(errors.Is(err, ErrBadRequest), Equals, true)
+	c.Assert(err, ErrorMatches, ".*: Error 1501: syntax error")
+}
+
+func (s *TypedCursorsSuite) TestQueryOne(c *C) {
+	params := &types.CursorQueryParams{Query: "FOR u IN users RETURN u", BatchSize: 50}
+	user, err := QueryOne[*typedUser](s.client, params)
+	c.Assert(err, IsNil)
+	c.Assert(*user, Equals, typedUser{"u1", 30})
+	// Only the first result is requested without changing the provided params.
+	c.Assert(s.tc.batchSize, Equals, 1)
+	c.Assert(params.BatchSize, Equals, 50)
+	count, err := QueryOne[int64](s.client, &types.CursorQueryParams{Query: "RETURN LENGTH(users)"})
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(9007199254740993))
+	// Every batch is checked before reporting that there are no results.
+	user, err = QueryOne[*typedUser](s.client, &types.CursorQueryParams{Query: "FOR u IN users FILTER u.age > 100 RETURN u"})
+	c.Assert(err, Equals, ErrNotFound)
+	c.Assert(user, IsNil)
+	_, err = QueryOne[string](s.client, &types.CursorQueryParams{Query: "FOR u IN user RETURN u"})
+	c.Assert(errors.Is(err, ErrBadRequest), Equals, true)
+}
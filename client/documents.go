@@ -3,13 +3,24 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/freshwebio/go-microfoxx/types"
 )
 
+const (
+	upsertEndpoint = "/upsert"
+	keyField       = "_key"
+	// The amount of results retrieved in each batch when querying
+	// documents by a list of keys.
+	keysBatchSize = 1000
+)
+
 // DocClient provides client functionality around handling
 // documents.
 type DocClient interface {
@@ -17,11 +28,17 @@ type DocClient interface {
 	GetDocs(string, *types.DocumentRetrievalParams) *types.DocumentsResult
 	GetDocCount(string, *types.DocumentRetrievalParams) *types.DocumentCountResult
 	RemoveDoc(string, string) *types.DocumentOpResult
-	GetDoc(string, string) *types.DocumentResult
+	GetDoc(string, string, ...string) *types.DocumentResult
 	UpdateDoc(string, string, interface{}) *types.DocumentOpResult
+	UpsertDoc(string, map[string]interface{}, interface{}, interface{}) *types.DocumentUpsertResult
+	DocExists(string, string) *types.DocumentExistsResult
+	DocRevision(string, string) *types.DocumentRevisionResult
+	DocsExist(string, []string) *types.DocumentsExistResult
+	GetDocsByKeys(string, []string) *types.DocumentsByKeyResult
 }
 
-// CreateDoc deals with creating a new document in the provided collection.
+// CreateDoc deals with creating a new document in the provided collection,
+// documents rejected by the schema of the collection fail with a *SchemaViolationError.
 func (c *clientImpl) CreateDoc(coll string, doc interface{}) *types.DocumentOpResult {
 	b := new(bytes.Buffer)
 	err := json.NewEncoder(b).Encode(doc)
@@ -59,13 +76,14 @@ func (c *clientImpl) CreateDoc(coll string, doc interface{}) *types.DocumentOpRe
 				return &types.DocumentOpResult{Err: err}
 			}
 			docOpInfo.Document = bd
+			docOpInfo.DecodedEvent = decodeEvent(be.Bytes())
 			docOpInfo.Event = be
 		}
 	} else {
 		docOpInfo.StatusCode = resp.StatusCode
 		msg, err := prepareExceptionResponse(resp)
 		docOpInfo.Message = msg
-		docOpInfo.Err = err
+		docOpInfo.Err = documentError(msg, err)
 	}
 	return &docOpInfo
 }
@@ -82,21 +100,18 @@ func (c *clientImpl) GetDocs(coll string, params *types.DocumentRetrievalParams)
 		// hyphens and underscores.
 		qParams.Add(field, url.QueryEscape(val))
 	}
+	err := addFilterParam(qParams, params.Filter)
+	if err != nil {
+		return &types.DocumentsResult{Err: err}
+	}
 	// Now the sort fields and orders.
-	sortFieldCount := len(params.SortFields)
-	if sortFieldCount > 0 {
-		sortValues := ""
-		for i, sortField := range params.SortFields {
-			sortValues += sortField
-			if i < sortFieldCount-1 {
-				sortValues += ","
-			}
-		}
-		// Now append the sort order if it is set.
-		if params.SortOrder != "" {
-			sortValues += "::" + params.SortOrder
-		}
-		qParams.Add("sort", sortValues)
+	err = addSortParam(qParams, params)
+	if err != nil {
+		return &types.DocumentsResult{Err: err}
+	}
+	err = addReturnParam(qParams, params.Return)
+	if err != nil {
+		return &types.DocumentsResult{Err: err}
 	}
 	// Finaly if a limit is provided then add that to the query string.
 	if params.LimitCount > 0 {
@@ -143,6 +158,13 @@ func (c *clientImpl) GetDocCount(coll string, params *types.DocumentRetrievalPar
 			qParams.Add(k, url.QueryEscape(v))
 		}
 	}
+	err := addFilterParam(qParams, params.Filter)
+	if err != nil {
+		return &types.DocumentCountResult{
+			Count: -1,
+			Err:   err,
+		}
+	}
 	req := c.prepareRequest("GET", "/"+coll+"/count", qParams, nil)
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -217,6 +239,7 @@ func (c *clientImpl) RemoveDoc(coll string, key string) *types.DocumentOpResult
 		if err != nil {
 			return &types.DocumentOpResult{Err: err}
 		}
+		docRes.DecodedEvent = decodeEvent(be.Bytes())
 		docRes.Event = be
 		docRes.StatusCode = resp.StatusCode
 		return &docRes
@@ -230,9 +253,15 @@ func (c *clientImpl) RemoveDoc(coll string, key string) *types.DocumentOpResult
 }
 
 // GetDoc deals with retrieving a single document from the specified collection
-// with the provided key.
-func (c *clientImpl) GetDoc(coll string, key string) *types.DocumentResult {
-	req := c.prepareRequest("GET", "/"+coll+"/"+key, nil, nil)
+// with the provided key. When fields are provided only those attributes of the document
+// are returned, each field can be a dot separated path to a nested attribute.
+func (c *clientImpl) GetDoc(coll string, key string, fields ...string) *types.DocumentResult {
+	qParams := make(url.Values)
+	err := addReturnParam(qParams, fields)
+	if err != nil {
+		return &types.DocumentResult{Err: err}
+	}
+	req := c.prepareRequest("GET", "/"+coll+"/"+key, qParams, nil)
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return &types.DocumentResult{
@@ -253,6 +282,131 @@ func (c *clientImpl) GetDoc(coll string, key string) *types.DocumentResult {
 	}
 }
 
+// DocExists deals with checking whether a document with the provided key exists in the specified
+// collection, the document itself isn't transferred as only the response headers are requested.
+func (c *clientImpl) DocExists(coll string, key string) *types.DocumentExistsResult {
+	req := c.prepareRequest("HEAD", "/"+coll+"/"+key, nil, nil)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &types.DocumentExistsResult{Err: err}
+	}
+	resp.Body.Close()
+	existsRes := types.DocumentExistsResult{StatusCode: resp.StatusCode}
+	if resp.StatusCode == http.StatusOK {
+		existsRes.Exists = true
+	} else if resp.StatusCode != http.StatusNotFound {
+		// HEAD responses don't have a body so only the status code is available.
+		existsRes.Err = statusError(resp.StatusCode)
+	}
+	return &existsRes
+}
+
+// DocRevision deals with retrieving the current revision of the document with the provided key
+// from the ETag response header without transferring the document itself.
+func (c *clientImpl) DocRevision(coll string, key string) *types.DocumentRevisionResult {
+	req := c.prepareRequest("HEAD", "/"+coll+"/"+key, nil, nil)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &types.DocumentRevisionResult{Err: err}
+	}
+	resp.Body.Close()
+	revRes := types.DocumentRevisionResult{StatusCode: resp.StatusCode}
+	if resp.StatusCode == http.StatusOK {
+		revRes.Revision = strings.Trim(resp.Header.Get("ETag"), "\"")
+		if revRes.Revision == "" {
+			revRes.Err = ErrGeneral
+		}
+	} else {
+		revRes.Err = statusError(resp.StatusCode)
+	}
+	return &revRes
+}
+
+// DocsExist deals with checking which of the provided keys belong to documents in the specified
+// collection with a single cursor query, which makes it suitable for deduplicating large batches
+// of documents before they are written.
+func (c *clientImpl) DocsExist(coll string, keys []string) *types.DocumentsExistResult {
+	existsRes := types.DocumentsExistResult{Exists: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		existsRes.Exists[key] = false
+	}
+	if len(keys) == 0 {
+		return &existsRes
+	}
+	results, failed := c.cursorQueryAll(&types.CursorQueryParams{
+		Query: "FOR d IN @@coll FILTER d._key IN @keys RETURN { _key: d._key }",
+		BindVars: map[string]interface{}{
+			"@coll": coll,
+			"keys":  keys,
+		},
+		BatchSize: keysBatchSize,
+	})
+	if failed != nil {
+		return &types.DocumentsExistResult{
+			Err:        failed.Err,
+			Message:    failed.Message,
+			StatusCode: failed.StatusCode,
+		}
+	}
+	for _, result := range results {
+		if key, ok := result[keyField].(string); ok {
+			existsRes.Exists[key] = true
+		}
+	}
+	existsRes.StatusCode = http.StatusOK
+	return &existsRes
+}
+
+// GetDocsByKeys deals with retrieving the documents with the provided keys from the specified
+// collection with a single cursor query rather than a request for each document.
+// Keys without a document are reported in the result instead of failing the whole batch.
+func (c *clientImpl) GetDocsByKeys(coll string, keys []string) *types.DocumentsByKeyResult {
+	docsRes := types.DocumentsByKeyResult{
+		Documents: make(map[string]io.Reader, len(keys)),
+		Missing:   []string{},
+	}
+	if len(keys) == 0 {
+		return &docsRes
+	}
+	results, failed := c.cursorQueryAll(&types.CursorQueryParams{
+		Query: "FOR d IN @@coll FILTER d._key IN @keys RETURN d",
+		BindVars: map[string]interface{}{
+			"@coll": coll,
+			"keys":  keys,
+		},
+		BatchSize: keysBatchSize,
+	})
+	if failed != nil {
+		return &types.DocumentsByKeyResult{
+			Err:        failed.Err,
+			Message:    failed.Message,
+			StatusCode: failed.StatusCode,
+		}
+	}
+	for _, result := range results {
+		key, ok := result[keyField].(string)
+		if !ok {
+			continue
+		}
+		bd := new(bytes.Buffer)
+		err := json.NewEncoder(bd).Encode(result)
+		if err != nil {
+			return &types.DocumentsByKeyResult{Err: err}
+		}
+		docsRes.Documents[key] = bd
+	}
+	// Report each missing key once in the order they were requested.
+	reported := make(map[string]bool)
+	for _, key := range keys {
+		if _, found := docsRes.Documents[key]; !found && !reported[key] {
+			docsRes.Missing = append(docsRes.Missing, key)
+			reported[key] = true
+		}
+	}
+	docsRes.StatusCode = http.StatusOK
+	return &docsRes
+}
+
 func (c *clientImpl) UpdateDoc(coll string, key string, doc interface{}) *types.DocumentOpResult {
 	b := new(bytes.Buffer)
 	err := json.NewEncoder(b).Encode(doc)
@@ -293,14 +447,141 @@ func (c *clientImpl) UpdateDoc(coll string, key string, doc interface{}) *types.
 				return &types.DocumentOpResult{Err: err}
 			}
 			docOpInfo.Document = bd
+			docOpInfo.DecodedEvent = decodeEvent(be.Bytes())
 			docOpInfo.Event = be
 		}
 		return &docOpInfo
 	}
 	msg, err := prepareExceptionResponse(resp)
 	return &types.DocumentOpResult{
+		Err:        documentError(msg, err),
+		Message:    msg,
+		StatusCode: resp.StatusCode,
+	}
+}
+
+// UpsertDoc deals with inserting or updating a document in a single operation.
+// The search attributes are matched against existing documents in the collection,
+// when a document matches it is updated with updateDoc, otherwise insertDoc is
+// inserted as a new document. The operation is carried out atomically by the service
+// so there is no race between checking for a document and creating it.
+func (c *clientImpl) UpsertDoc(coll string, search map[string]interface{}, insertDoc interface{},
+	updateDoc interface{}) *types.DocumentUpsertResult {
+	if len(search) == 0 {
+		return &types.DocumentUpsertResult{Err: ErrEmptyUpsertSearch}
+	}
+	b := new(bytes.Buffer)
+	err := json.NewEncoder(b).Encode(struct {
+		Search map[string]interface{} `json:"search"`
+		Insert interface{}            `json:"insert"`
+		Update interface{}            `json:"update"`
+	}{Search: search, Insert: insertDoc, Update: updateDoc})
+	if err != nil {
+		return &types.DocumentUpsertResult{Err: err}
+	}
+	req := c.prepareRequest("POST", "/"+coll+upsertEndpoint, nil, b)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &types.DocumentUpsertResult{Err: err}
+	}
+	if resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusOK {
+		upsertRes := types.DocumentUpsertResult{}
+		upsertRes.StatusCode = resp.StatusCode
+		var intermediary = struct {
+			Document map[string]interface{} `json:"doc"`
+			Event    map[string]interface{} `json:"event"`
+			Inserted bool                   `json:"inserted"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&intermediary)
+		if err != nil {
+			return &types.DocumentUpsertResult{Err: err}
+		}
+		bd := new(bytes.Buffer)
+		be := new(bytes.Buffer)
+		err = json.NewEncoder(bd).Encode(intermediary.Document)
+		if err != nil {
+			return &types.DocumentUpsertResult{Err: err}
+		}
+		err = json.NewEncoder(be).Encode(intermediary.Event)
+		if err != nil {
+			return &types.DocumentUpsertResult{Err: err}
+		}
+		upsertRes.Inserted = intermediary.Inserted
+		upsertRes.Document = bd
+		upsertRes.DecodedEvent = decodeEvent(be.Bytes())
+		upsertRes.Event = be
+		return &upsertRes
+	}
+	msg, err := prepareExceptionResponse(resp)
+	return &types.DocumentUpsertResult{
 		Err:        err,
 		Message:    msg,
 		StatusCode: resp.StatusCode,
 	}
 }
+
+// Deals with validating the provided filter expression and adding it
+// to the query string as JSON, nil filters are simply skipped.
+func addFilterParam(qParams url.Values, filter *types.Filter) error {
+	if filter == nil {
+		return nil
+	}
+	err := filter.Validate()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(filter)
+	if err != nil {
+		return err
+	}
+	qParams.Add("filter", string(b))
+	return nil
+}
+
+// Deals with validating the sort parameters and adding them to the query string.
+// SortFields are sent as field1,field2::ORDER where the order applies to every field
+// whereas Sort is sent as field1::ORDER,field2::ORDER with an order for each field.
+func addSortParam(qParams url.Values, params *types.DocumentRetrievalParams) error {
+	if len(params.Sort) > 0 && len(params.SortFields) > 0 {
+		return fmt.Errorf("%w: SortFields and Sort can't be used together", types.ErrInvalidSort)
+	}
+	sortValues := make([]string, 0, len(params.Sort))
+	for _, sortField := range params.Sort {
+		err := sortField.Validate()
+		if err != nil {
+			return err
+		}
+		// Validation has already ensured the direction is known.
+		direction, _ := types.ParseSortDirection(string(sortField.Direction))
+		sortValues = append(sortValues, sortField.Field+"::"+string(direction))
+	}
+	if len(params.SortFields) > 0 {
+		sortValues = append(sortValues, params.SortFields...)
+		// Now append the sort order if it is set.
+		if params.SortOrder != "" {
+			direction, err := types.ParseSortDirection(params.SortOrder)
+			if err != nil {
+				return err
+			}
+			sortValues[len(sortValues)-1] += "::" + string(direction)
+		}
+	}
+	if len(sortValues) > 0 {
+		qParams.Add("sort", strings.Join(sortValues, ","))
+	}
+	return nil
+}
+
+// Deals with validating the projection fields and adding them to the query string
+// as a comma separated list, no parameter is added when there are no fields.
+func addReturnParam(qParams url.Values, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	err := types.ValidateProjection(fields)
+	if err != nil {
+		return err
+	}
+	qParams.Add("return", strings.Join(fields, ","))
+	return nil
+}
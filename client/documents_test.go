@@ -3,6 +3,7 @@ package client_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"regexp"
@@ -48,12 +49,21 @@ func (c *documentsTestClient) Do(req *http.Request) (resp *http.Response, err er
 		docRegExp := regexp.MustCompile("^/(\\w+)(\\?(.*))?$")
 		countRegExp := regexp.MustCompile("^/(\\w+)/count(\\?(.*))?$")
 		docKeyRegExp := regexp.MustCompile("^/(\\w+)/(\\w+)(\\?(.*))?$")
-		if countRegExp.MatchString(path) && r.Method == "GET" {
+		upsertRegExp := regexp.MustCompile("^/(\\w+)/upsert$")
+		if path == "/cursor" && r.Method == "POST" {
+			c.keysCursor(w, r)
+		} else if upsertRegExp.MatchString(path) && r.Method == "POST" {
+			parts := upsertRegExp.FindStringSubmatch(path)
+			c.upsertDoc(w, r, parts[1])
+		} else if countRegExp.MatchString(path) && r.Method == "GET" {
 			parts := countRegExp.FindStringSubmatch(path)
 			c.countDocs(w, r, parts[1])
 		} else if docKeyRegExp.MatchString(path) && r.Method == "DELETE" {
 			parts := docKeyRegExp.FindStringSubmatch(path)
 			c.removeDoc(w, r, parts[1], parts[2])
+		} else if docKeyRegExp.MatchString(path) && r.Method == "HEAD" {
+			parts := docKeyRegExp.FindStringSubmatch(path)
+			c.headDoc(w, r, parts[1], parts[2])
 		} else if docKeyRegExp.MatchString(path) && r.Method == "GET" {
 			parts := docKeyRegExp.FindStringSubmatch(path)
 			c.getDoc(w, r, parts[1], parts[2])
@@ -85,9 +95,41 @@ func (c *documentsTestClient) getDocs(w http.ResponseWriter, req *http.Request,
 		w.Write([]byte("{\"exception\":\"Error 2016: that collection doesn't exist\"}"))
 		return
 	}
+	// Only the sort parameters used by the tests are considered valid.
+	if sort := req.URL.Query().Get("sort"); sort != "" && sort != "height::DESC" && sort != "stats.rating::DESC,name::ASC" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte("{\"exception\":\"Error 2016: Invalid sort parameters\"}"))
+		return
+	}
+	// Filter expressions are sent as JSON, for the purpose of testing only
+	// a range filter on a nested attribute is expected to match any documents.
+	if filterParam := req.URL.Query().Get("filter"); filterParam != "" {
+		var filter types.Filter
+		json.NewDecoder(strings.NewReader(filterParam)).Decode(&filter)
+		if filter.Op == types.FilterAnd && len(filter.Filters) == 2 && filter.Filters[0].Field == "stats.rating" {
+			w.WriteHeader(http.StatusOK)
+			documents := []*documentTestModel{
+				{Id: "test/ab54fgd3", Key: "ab54fgd3", Rating: "5", Height: "180cm"},
+				{Id: "test/aq53agd3", Key: "aq53agd3", Rating: "5", Height: "177cm"},
+			}
+			respBytes, _ := json.Marshal(documents)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write(respBytes)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte("{\"exception\":\"Error 2016: No documents found\"}"))
+		}
+		return
+	}
 	// Now try to retrieve the expected field parameter provided by the test.
 	rating := req.URL.Query().Get("rating")
-	if rating == "high" {
+	if rating == "high" && req.URL.Query().Get("return") == "_key,rating" {
+		w.WriteHeader(http.StatusOK)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte("[{\"_key\":\"ab54fgd3\",\"rating\":\"5\"},{\"_key\":\"bd53fgd3\",\"rating\":\"4\"}]"))
+	} else if rating == "high" {
 		w.WriteHeader(http.StatusOK)
 		documents := []*documentTestModel{
 			{
@@ -133,7 +175,11 @@ func (c *documentsTestClient) getDocs(w http.ResponseWriter, req *http.Request,
 
 func (c *documentsTestClient) countDocs(w http.ResponseWriter, req *http.Request, coll string) {
 	if coll == "test" {
-		if req.URL.Query().Get("rating") != "" {
+		if req.URL.Query().Get("filter") != "" {
+			w.WriteHeader(http.StatusOK)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte("{\"count\":12}"))
+		} else if req.URL.Query().Get("rating") != "" {
 			w.WriteHeader(http.StatusOK)
 			w.Header().Set("Content-Type", "application/json; charset=utf-8")
 			w.Write([]byte("{\"count\":65}"))
@@ -149,8 +195,17 @@ func (c *documentsTestClient) countDocs(w http.ResponseWriter, req *http.Request
 	}
 }
 
+// The response for documents that don't match the schema of the test collection,
+// only documents with a rating of invalid are rejected.
+const schemaViolationResp = "{\"exception\":\"Error 1620: Schema violation: rating must be one of low, high\"}"
+
 func (c *documentsTestClient) createDoc(w http.ResponseWriter, req *http.Request, coll string) {
-	if coll == "test" {
+	doc := documentTestModel{}
+	json.NewDecoder(req.Body).Decode(&doc)
+	if coll == "test" && doc.Rating == "invalid" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(schemaViolationResp))
+	} else if coll == "test" {
 		w.WriteHeader(http.StatusCreated)
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		respData := make(map[string]interface{})
@@ -190,7 +245,14 @@ func (c *documentsTestClient) removeDoc(w http.ResponseWriter, req *http.Request
 func (c *documentsTestClient) getDoc(w http.ResponseWriter, req *http.Request, coll string, key string) {
 	if coll == "test" {
 		if key == "ab321e" {
-			doc := documentTestModel{}
+			var doc interface{} = documentTestModel{Key: "ab321e", Rating: "5", Height: "180cm"}
+			// Only the projections used by the tests are supported.
+			if req.URL.Query().Get("return") == "rating,stats.views" {
+				doc = map[string]interface{}{
+					"rating": "5",
+					"stats":  map[string]interface{}{"views": 42},
+				}
+			}
 			w.WriteHeader(http.StatusOK)
 			w.Header().Set("Content-Type", "application/json; charset=utf-8")
 			b := new(bytes.Buffer)
@@ -215,8 +277,22 @@ func (c *documentsTestClient) updateDoc(w http.ResponseWriter, req *http.Request
 			respBody := make(map[string]interface{})
 			doc := documentTestModel{}
 			json.NewDecoder(req.Body).Decode(&doc)
+			if doc.Rating == "invalid" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(schemaViolationResp))
+				return
+			}
 			respBody["doc"] = doc
-			respBody["event"] = &eventTestModel{}
+			respBody["event"] = &types.Event{
+				Type:       "document",
+				Op:         types.EventUpdate,
+				Collection: coll,
+				Key:        key,
+				OldRev:     "_WcQ2-Fa---",
+				NewRev:     "_WcQ2-Fb---",
+				Created:    1500000000000,
+				Data:       json.RawMessage("{\"rating\":\"" + doc.Rating + "\"}"),
+			}
 			w.WriteHeader(http.StatusOK)
 			w.Header().Set("Content-Type", "application/json; charset=utf-8")
 			b := new(bytes.Buffer)
@@ -234,6 +310,79 @@ func (c *documentsTestClient) updateDoc(w http.ResponseWriter, req *http.Request
 	}
 }
 
+func (c *documentsTestClient) upsertDoc(w http.ResponseWriter, req *http.Request, coll string) {
+	if coll == "test" {
+		var params struct {
+			Search map[string]interface{} `json:"search"`
+			Insert documentTestModel      `json:"insert"`
+			Update documentTestModel      `json:"update"`
+		}
+		json.NewDecoder(req.Body).Decode(&params)
+		respBody := make(map[string]interface{})
+		respBody["event"] = &eventTestModel{}
+		// For the purpose of testing only a search on the ab321e key matches
+		// an existing document.
+		if params.Search["_key"] == "ab321e" {
+			respBody["doc"] = params.Update
+			respBody["inserted"] = false
+			w.WriteHeader(http.StatusOK)
+		} else {
+			respBody["doc"] = params.Insert
+			respBody["inserted"] = true
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		b := new(bytes.Buffer)
+		json.NewEncoder(b).Encode(respBody)
+		w.Write(b.Bytes())
+	} else {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte("{\"exception\":\"Error 2016: that collection doesn't exist\"}"))
+	}
+}
+
+func (c *documentsTestClient) headDoc(w http.ResponseWriter, req *http.Request, coll string, key string) {
+	if coll != "test" {
+		w.WriteHeader(http.StatusBadRequest)
+	} else if key == "ab321e" {
+		w.Header().Set("ETag", "\"_WcQ2-Fa---\"")
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// Deals with responding to the cursor queries used to look up documents by key.
+func (c *documentsTestClient) keysCursor(w http.ResponseWriter, req *http.Request) {
+	existing := map[string]bool{"ab321e": true, "gt543d": true}
+	var params types.CursorQueryParams
+	json.NewDecoder(req.Body).Decode(&params)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if params.BindVars["@coll"] != "test" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("{\"exception\":\"Error 2016: that collection doesn't exist\"}"))
+		return
+	}
+	results := make([]map[string]interface{}, 0)
+	for _, key := range params.BindVars["keys"].([]interface{}) {
+		if existing[key.(string)] {
+			// Full documents are only returned for queries that ask for them.
+			if strings.HasSuffix(params.Query, "RETURN d") {
+				results = append(results, map[string]interface{}{"_key": key, "rating": "high", "height": "180cm"})
+			} else {
+				results = append(results, map[string]interface{}{"_key": key})
+			}
+		}
+	}
+	w.WriteHeader(http.StatusCreated)
+	b, _ := json.Marshal(map[string]interface{}{
+		"results": results,
+		"hasMore": false,
+	})
+	w.Write(b)
+}
+
 var _ = Suite(&DocumentsSuite{})
 
 func (s *DocumentsSuite) SetUpSuite(c *C) {
@@ -287,6 +436,84 @@ func (s *DocumentsSuite) TestGetDocs(c *C) {
 	c.Assert(res.Documents, Equals, nil)
 }
 
+func (s *DocumentsSuite) TestGetDocsWithFilter(c *C) {
+	params := &types.DocumentRetrievalParams{
+		Filter: types.And(
+			types.Gte("stats.rating", 5),
+			types.Or(types.In("status", "active", "pending"), types.Exists("deletedAt", false)),
+		),
+	}
+	res := s.client.GetDocs("test", params)
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	var docs []documentTestModel
+	err := json.NewDecoder(res.Documents).Decode(&docs)
+	if err != nil {
+		c.Error("Failed to decode response documents")
+	}
+	c.Assert(len(docs), Equals, 2)
+	countRes := s.client.GetDocCount("test", params)
+	c.Assert(countRes.Err, Equals, nil)
+	c.Assert(countRes.Count, Equals, 12)
+	// Now ensure invalid filters are rejected before a request is made.
+	invalidFilters := []*types.Filter{
+		types.And(),
+		types.Gte("stats..rating", 5),
+		types.Lt("rating", nil),
+		{Op: types.FilterLike, Field: "name", Value: 5},
+		{Op: types.FilterIn, Field: "status", Value: "active"},
+		{Op: "between", Field: "rating", Value: 5},
+		types.Or(types.Eq("rating", 5), nil),
+	}
+	for _, filter := range invalidFilters {
+		params.Filter = filter
+		res = s.client.GetDocs("test", params)
+		c.Assert(errors.Is(res.Err, types.ErrInvalidFilter), Equals, true)
+		c.Assert(res.StatusCode, Equals, 0)
+		c.Assert(res.Documents, Equals, nil)
+		countRes = s.client.GetDocCount("test", params)
+		c.Assert(errors.Is(countRes.Err, types.ErrInvalidFilter), Equals, true)
+		c.Assert(countRes.Count, Equals, -1)
+	}
+}
+
+func (s *DocumentsSuite) TestGetDocsSort(c *C) {
+	params := &types.DocumentRetrievalParams{
+		Fields: map[string]string{
+			"rating": "high",
+		},
+		Sort: []*types.SortField{
+			types.Desc("stats.rating"),
+			{Field: "name"},
+		},
+	}
+	res := s.client.GetDocs("test", params)
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	// Directions are case-insensitive and normalised before being sent.
+	params.Sort = []*types.SortField{
+		{Field: "stats.rating", Direction: "desc"},
+		{Field: "name", Direction: "asc"},
+	}
+	res = s.client.GetDocs("test", params)
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	// Now ensure unknown directions, invalid fields and mixing the two
+	// sort styles are rejected before a request is made.
+	invalidParams := []*types.DocumentRetrievalParams{
+		{Sort: []*types.SortField{{Field: "name", Direction: "DOWN"}}},
+		{Sort: []*types.SortField{{Field: "name with spaces"}}},
+		{Sort: []*types.SortField{nil}},
+		{SortFields: []string{"height"}, SortOrder: "sideways"},
+		{SortFields: []string{"height"}, Sort: []*types.SortField{types.Asc("name")}},
+	}
+	for _, invalid := range invalidParams {
+		res = s.client.GetDocs("test", invalid)
+		c.Assert(errors.Is(res.Err, types.ErrInvalidSort), Equals, true)
+		c.Assert(res.StatusCode, Equals, 0)
+	}
+}
+
 func (s *DocumentsSuite) TestCreateDocs(c *C) {
 	doc := documentTestModel{}
 	doc.Rating = "high"
@@ -294,6 +521,7 @@ func (s *DocumentsSuite) TestCreateDocs(c *C) {
 	res := s.client.CreateDoc("test", doc)
 	c.Assert(res.Err, Equals, nil)
 	c.Assert(res.StatusCode, Equals, http.StatusCreated)
+	c.Assert(res.DecodedEvent, NotNil)
 	// Make sure we can extract the event.
 	var event eventTestModel
 	err := json.NewDecoder(res.Event).Decode(&event)
@@ -388,6 +616,125 @@ func (s *DocumentsSuite) TestGetDoc(c *C) {
 	}
 }
 
+func (s *DocumentsSuite) TestGetDocWithProjection(c *C) {
+	res := s.client.GetDoc("test", "ab321e", "rating", "stats.views")
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	// Ensure the partial document can be decoded into a partial struct
+	// including the nested attribute.
+	var doc struct {
+		Rating string `json:"rating"`
+		Stats  struct {
+			Views int `json:"views"`
+		} `json:"stats"`
+	}
+	err := json.NewDecoder(res.Document).Decode(&doc)
+	if err != nil {
+		c.Error("Failed to decode the partial document")
+	}
+	c.Assert(doc.Rating, Equals, "5")
+	c.Assert(doc.Stats.Views, Equals, 42)
+	// Invalid and repeated fields are rejected before a request is made.
+	res = s.client.GetDoc("test", "ab321e", "rating", "stats..views")
+	c.Assert(errors.Is(res.Err, types.ErrInvalidProjection), Equals, true)
+	c.Assert(res.StatusCode, Equals, 0)
+	res = s.client.GetDoc("test", "ab321e", "rating", "rating")
+	c.Assert(errors.Is(res.Err, types.ErrInvalidProjection), Equals, true)
+}
+
+func (s *DocumentsSuite) TestGetDocsWithProjection(c *C) {
+	params := &types.DocumentRetrievalParams{
+		Fields: map[string]string{
+			"rating": "high",
+		},
+		Return: []string{"_key", "rating"},
+	}
+	res := s.client.GetDocs("test", params)
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	var docs []struct {
+		Key    string `json:"_key"`
+		Rating string `json:"rating"`
+	}
+	err := json.NewDecoder(res.Documents).Decode(&docs)
+	if err != nil {
+		c.Error("Failed to decode the partial documents")
+	}
+	c.Assert(len(docs), Equals, 2)
+	c.Assert(docs[1].Key, Equals, "bd53fgd3")
+	c.Assert(docs[1].Rating, Equals, "4")
+	params.Return = []string{""}
+	res = s.client.GetDocs("test", params)
+	c.Assert(errors.Is(res.Err, types.ErrInvalidProjection), Equals, true)
+	c.Assert(res.Documents, Equals, nil)
+}
+
+func (s *DocumentsSuite) TestDocExists(c *C) {
+	res := s.client.DocExists("test", "ab321e")
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(res.Exists, Equals, true)
+	// A missing document isn't an error when checking for existence.
+	res = s.client.DocExists("test", "bg542eq")
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusNotFound)
+	c.Assert(res.Exists, Equals, false)
+	res = s.client.DocExists("cars", "ab321e")
+	c.Assert(res.Err, Equals, ErrBadRequest)
+	c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
+	c.Assert(res.Exists, Equals, false)
+}
+
+func (s *DocumentsSuite) TestDocRevision(c *C) {
+	res := s.client.DocRevision("test", "ab321e")
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(res.Revision, Equals, "_WcQ2-Fa---")
+	res = s.client.DocRevision("test", "bg542eq")
+	c.Assert(res.Err, Equals, ErrNotFound)
+	c.Assert(res.StatusCode, Equals, http.StatusNotFound)
+	c.Assert(res.Revision, Equals, "")
+}
+
+func (s *DocumentsSuite) TestDocsExist(c *C) {
+	res := s.client.DocsExist("test", []string{"ab321e", "bg542eq", "gt543d"})
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(res.Exists, DeepEquals, map[string]bool{
+		"ab321e":  true,
+		"bg542eq": false,
+		"gt543d":  true,
+	})
+	// No request is needed for an empty list of keys.
+	res = s.client.DocsExist("cars", []string{})
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(len(res.Exists), Equals, 0)
+	res = s.client.DocsExist("cars", []string{"ab321e"})
+	c.Assert(res.Err, Equals, ErrBadRequest)
+	c.Assert(res.Message, Equals, "Error 2016: that collection doesn't exist")
+	c.Assert(res.Exists, IsNil)
+}
+
+func (s *DocumentsSuite) TestGetDocsByKeys(c *C) {
+	res := s.client.GetDocsByKeys("test", []string{"ab321e", "bg542eq", "gt543d", "bg542eq"})
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(len(res.Documents), Equals, 2)
+	c.Assert(res.Missing, DeepEquals, []string{"bg542eq"})
+	var doc documentTestModel
+	err := json.NewDecoder(res.Documents["gt543d"]).Decode(&doc)
+	if err != nil {
+		c.Error("Failed to decode the document")
+	}
+	c.Assert(doc.Key, Equals, "gt543d")
+	c.Assert(doc.Rating, Equals, "high")
+	// Failures for the whole request are still reported as errors.
+	res = s.client.GetDocsByKeys("cars", []string{"ab321e"})
+	c.Assert(res.Err, Equals, ErrBadRequest)
+	c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
+	c.Assert(res.Documents, IsNil)
+}
+
 func (s *DocumentsSuite) TestUpdateDoc(c *C) {
 	// Try to update a document in a collection that doesn't exist.
 	res := s.client.UpdateDoc("cars", "ab321e", documentTestModel{})
@@ -420,9 +767,78 @@ func (s *DocumentsSuite) TestUpdateDoc(c *C) {
 	}
 	c.Assert(doc.Rating, Equals, "high")
 	c.Assert(doc.Height, Equals, "186cm")
+	// Ensure the event is decoded automatically and the raw event is still available.
+	c.Assert(res.DecodedEvent, NotNil)
+	c.Assert(res.DecodedEvent.Op, Equals, types.EventUpdate)
+	c.Assert(res.DecodedEvent.Collection, Equals, "test")
+	c.Assert(res.DecodedEvent.Key, Equals, "ab321e")
+	c.Assert(res.DecodedEvent.OldRev, Equals, "_WcQ2-Fa---")
+	c.Assert(res.DecodedEvent.NewRev, Equals, "_WcQ2-Fb---")
+	c.Assert(res.DecodedEvent.CreatedAt().Equal(time.Unix(1500000000, 0)), Equals, true)
+	c.Assert(string(res.DecodedEvent.Data), Equals, "{\"rating\":\"high\"}")
+	var evt types.Event
+	err = json.NewDecoder(res.Event).Decode(&evt)
+	if err != nil {
+		c.Error("Failed to decode the event")
+	}
+	c.Assert(evt.Key, Equals, "ab321e")
+}
+
+func (s *DocumentsSuite) TestSchemaViolation(c *C) {
+	for _, res := range []*types.DocumentOpResult{
+		s.client.CreateDoc("test", documentTestModel{Rating: "invalid"}),
+		s.client.UpdateDoc("test", "ab321e", documentTestModel{Rating: "invalid"}),
+	} {
+		c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
+		c.Assert(res.Message, Equals, "Error 1620: Schema violation: rating must be one of low, high")
+		var violation *SchemaViolationError
+		c.Assert(errors.As(res.Err, &violation), Equals, true)
+		c.Assert(violation.Message, Equals, res.Message)
+		// Schema violations are still bad requests.
+		c.Assert(errors.Is(res.Err, ErrBadRequest), Equals, true)
+		c.Assert(res.Document, IsNil)
+	}
+}
+
+func (s *DocumentsSuite) TestUpsertDoc(c *C) {
+	// Try to upsert a document in a collection that doesn't exist.
+	search := map[string]interface{}{"_key": "ab321e"}
+	res := s.client.UpsertDoc("cars", search, documentTestModel{}, documentTestModel{})
+	c.Assert(res.Err, Equals, ErrBadRequest)
+	c.Assert(res.Message, Equals, "Error 2016: that collection doesn't exist")
+	c.Assert(res.Document, Equals, nil)
+	c.Assert(res.Event, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
+	// An upsert without any search attributes should never reach the service.
+	res = s.client.UpsertDoc("test", map[string]interface{}{}, documentTestModel{}, documentTestModel{})
+	c.Assert(res.Err, Equals, ErrEmptyUpsertSearch)
+	c.Assert(res.StatusCode, Equals, 0)
+	// Now upsert with a search matching an existing document which should be updated.
+	res = s.client.UpsertDoc("test", search, documentTestModel{Rating: "low"}, documentTestModel{Rating: "high"})
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(res.Inserted, Equals, false)
+	var doc documentTestModel
+	err := json.NewDecoder(res.Document).Decode(&doc)
+	if err != nil {
+		c.Error("Failed to decode the document")
+	}
+	c.Assert(doc.Rating, Equals, "high")
 	var evt eventTestModel
 	err = json.NewDecoder(res.Event).Decode(&evt)
 	if err != nil {
 		c.Error("Failed to decode the event")
 	}
+	// Finally upsert with a search that doesn't match so a new document is inserted.
+	search["_key"] = "zz999a"
+	res = s.client.UpsertDoc("test", search, documentTestModel{Rating: "low"}, documentTestModel{Rating: "high"})
+	c.Assert(res.Err, Equals, nil)
+	c.Assert(res.StatusCode, Equals, http.StatusCreated)
+	c.Assert(res.Inserted, Equals, true)
+	doc = documentTestModel{}
+	err = json.NewDecoder(res.Document).Decode(&doc)
+	if err != nil {
+		c.Error("Failed to decode the document")
+	}
+	c.Assert(doc.Rating, Equals, "low")
 }
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/freshwebio/go-microfoxx/types"
+)
+
+const (
+	explainEndpoint = "/explain"
+	profileEndpoint = "/profile"
+	parseEndpoint   = "/parse"
+)
+
+// Matches the position ArangoDB reports syntax errors at.
+var syntaxErrorPositionRegExp = regexp.MustCompile("at position (\\d+):(\\d+)")
+
+// ExplainClient provides the functionality to inspect how
+// the data store service runs an AQL query.
+type ExplainClient interface {
+	ExplainQuery(*types.CursorQueryParams) *types.ExplainResult
+	ProfileQuery(*types.CursorQueryParams) *types.ProfileResult
+	ParseQuery(string) *types.ParseResult
+}
+
+// QuerySyntaxError is the error returned when parsing a query fails because of a syntax error,
+// Line and Column provide the position of the error starting from 1 and Message is the message
+// provided by the data store service.
+type QuerySyntaxError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return "Syntax error in query at line " + strconv.Itoa(e.Line) + ", column " +
+		strconv.Itoa(e.Column) + ": " + e.Message
+}
+
+// ExplainQuery deals with retrieving the execution plan the optimizer chooses for the
+// provided query without running it, the batch size and count of the params are ignored.
+func (c *clientImpl) ExplainQuery(params *types.CursorQueryParams) *types.ExplainResult {
+	b := new(bytes.Buffer)
+	err := json.NewEncoder(b).Encode(explainParams(params))
+	if err != nil {
+		return &types.ExplainResult{Err: err}
+	}
+	req := c.prepareRequest("POST", explainEndpoint, nil, b)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &types.ExplainResult{Err: err}
+	}
+	var explainRes types.ExplainResult
+	explainRes.StatusCode = resp.StatusCode
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		intermediary := struct {
+			Plan      *types.Plan           `json:"plan"`
+			Cacheable bool                  `json:"cacheable"`
+			Warnings  []*types.QueryWarning `json:"warnings"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&intermediary)
+		if err != nil {
+			return &types.ExplainResult{Err: err}
+		}
+		explainRes.Plan = intermediary.Plan
+		explainRes.Cacheable = intermediary.Cacheable
+		explainRes.Warnings = intermediary.Warnings
+	} else {
+		msg, err := prepareExceptionResponse(resp)
+		explainRes.Message = msg
+		explainRes.Err = err
+	}
+	return &explainRes
+}
+
+// ProfileQuery deals with running the provided query to completion and retrieving its
+// results along with the execution plan where every node provides its runtime statistics.
+// All of the results are provided at once so the batch size of the params is ignored.
+func (c *clientImpl) ProfileQuery(params *types.CursorQueryParams) *types.ProfileResult {
+	b := new(bytes.Buffer)
+	err := json.NewEncoder(b).Encode(explainParams(params))
+	if err != nil {
+		return &types.ProfileResult{Err: err}
+	}
+	req := c.prepareRequest("POST", profileEndpoint, nil, b)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &types.ProfileResult{Err: err}
+	}
+	var profileRes types.ProfileResult
+	profileRes.StatusCode = resp.StatusCode
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		intermediary := struct {
+			Results []interface{} `json:"results"`
+			Plan    *types.Plan   `json:"plan"`
+			Stats   struct {
+				Nodes []struct {
+					ID int `json:"id"`
+					types.PlanNodeStats
+				} `json:"nodes"`
+				ExecutionTime float64 `json:"executionTime"`
+			} `json:"stats"`
+			Profile  map[string]float64    `json:"profile"`
+			Warnings []*types.QueryWarning `json:"warnings"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&intermediary)
+		if err != nil {
+			return &types.ProfileResult{Err: err}
+		}
+		docBytes := new(bytes.Buffer)
+		err = json.NewEncoder(docBytes).Encode(intermediary.Results)
+		if err != nil {
+			return &types.ProfileResult{Err: err}
+		}
+		// The runtime statistics are provided separately from the plan
+		// so attach them to the nodes they belong to.
+		if intermediary.Plan != nil {
+			stats := make(map[int]types.PlanNodeStats)
+			for _, node := range intermediary.Stats.Nodes {
+				stats[node.ID] = node.PlanNodeStats
+			}
+			for _, node := range intermediary.Plan.Nodes {
+				if nodeStats, ok := stats[node.ID]; ok {
+					node.Stats = &nodeStats
+				}
+			}
+		}
+		profileRes.Documents = docBytes
+		profileRes.Plan = intermediary.Plan
+		profileRes.Phases = intermediary.Profile
+		profileRes.ExecutionTime = intermediary.Stats.ExecutionTime
+		profileRes.Warnings = intermediary.Warnings
+	} else {
+		msg, err := prepareExceptionResponse(resp)
+		profileRes.Message = msg
+		profileRes.Err = err
+	}
+	return &profileRes
+}
+
+// ParseQuery deals with checking the syntax of the provided query without running it,
+// the result provides the collections the query references and the bind parameters it
+// requires. When the query has a syntax error the error of the result is a *QuerySyntaxError.
+func (c *clientImpl) ParseQuery(query string) *types.ParseResult {
+	b := new(bytes.Buffer)
+	err := json.NewEncoder(b).Encode(map[string]string{"query": query})
+	if err != nil {
+		return &types.ParseResult{Err: err}
+	}
+	req := c.prepareRequest("POST", parseEndpoint, nil, b)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &types.ParseResult{Err: err}
+	}
+	var parseRes types.ParseResult
+	parseRes.StatusCode = resp.StatusCode
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		intermediary := struct {
+			Collections []string `json:"collections"`
+			BindVars    []string `json:"bindVars"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&intermediary)
+		if err != nil {
+			return &types.ParseResult{Err: err}
+		}
+		parseRes.Collections = intermediary.Collections
+		parseRes.BindVars = intermediary.BindVars
+	} else {
+		msg, err := prepareExceptionResponse(resp)
+		parseRes.Message = msg
+		parseRes.Err = err
+		position := syntaxErrorPositionRegExp.FindStringSubmatch(msg)
+		if resp.StatusCode == http.StatusBadRequest && position != nil {
+			line, _ := strconv.Atoi(position[1])
+			column, _ := strconv.Atoi(position[2])
+			parseRes.Err = &QuerySyntaxError{Line: line, Column: column, Message: msg}
+		}
+	}
+	return &parseRes
+}
+
+// Deals with preparing the body of explain and profile requests
+// which only need the query and its bind variables.
+func explainParams(params *types.CursorQueryParams) interface{} {
+	return struct {
+		Query    string                 `json:"query"`
+		BindVars map[string]interface{} `json:"bindVars"`
+	}{Query: params.Query, BindVars: params.BindVars}
+}
@@ -0,0 +1,193 @@
+package client_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/freshwebio/go-microfoxx/client"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+type ExplainSuite struct {
+	client Client
+}
+
+type explainTestClient struct {
+	dummySessionClient
+}
+
+func newExplainTestHttpClient() WebClient {
+	return &explainTestClient{}
+}
+
+const explainTestPlan = `{
+	"nodes": [
+		{"id": 1, "type": "SingletonNode", "dependencies": [], "estimatedCost": 1, "estimatedNrItems": 1},
+		{"id": 6, "type": "IndexNode", "dependencies": [1], "estimatedCost": 11.5, "estimatedNrItems": 10,
+			"collection": "users", "indexes": [{"id": "101", "name": "idx_age", "type": "persistent",
+			"fields": ["age"], "unique": false, "sparse": false, "selectivityEstimate": 0.4}]},
+		{"id": 4, "type": "LimitNode", "dependencies": [6], "estimatedCost": 12.5, "estimatedNrItems": 5},
+		{"id": 5, "type": "ReturnNode", "dependencies": [4], "estimatedCost": 17.5, "estimatedNrItems": 5}
+	],
+	"rules": ["use-indexes", "remove-filter-covered-by-index"],
+	"collections": [{"name": "users", "type": "read"}],
+	"estimatedCost": 17.5,
+	"estimatedNrItems": 5
+}`
+
+// Deals with preparing a response for explain and profile requests.
+func (c *explainTestClient) Do(req *http.Request) (resp *http.Response, err error) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			Query    string                 `json:"query"`
+			BindVars map[string]interface{} `json:"bindVars"`
+		}
+		json.NewDecoder(r.Body).Decode(&params)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if strings.HasSuffix(r.URL.Path, "/parse") {
+			c.parse(w, params.Query)
+			return
+		}
+		if !strings.HasPrefix(params.Query, "FOR u IN users") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("{\"exception\":\"Error 1501: syntax error, unexpected identifier\"}"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		switch strings.TrimPrefix(r.URL.Path, "/_db//microfoxx") {
+		case "/explain":
+			w.Write([]byte("{\"plan\":" + explainTestPlan + ",\"cacheable\":true," +
+				"\"warnings\":[{\"code\":1562,\"message\":\"division by zero\"}]}"))
+		case "/profile":
+			w.Write([]byte("{\"results\":[{\"_key\":\"u1\",\"age\":30}]," +
+				"\"plan\":" + explainTestPlan + "," +
+				"\"stats\":{\"nodes\":[{\"id\":1,\"calls\":1,\"items\":1,\"runtime\":0.0001}," +
+				"{\"id\":6,\"calls\":1,\"items\":1,\"runtime\":0.002},{\"id\":5,\"calls\":1,\"items\":1,\"runtime\":0.0025}]," +
+				"\"executionTime\":0.003}," +
+				"\"profile\":{\"parsing\":0.0001,\"optimizing plan\":0.0004,\"executing\":0.0025}}"))
+		}
+	}))
+	defer server.Close()
+	newReq, _ := http.NewRequest(req.Method, server.URL+req.URL.Path, req.Body)
+	newReq.URL.RawQuery = req.URL.RawQuery
+	resp, err = http.DefaultClient.Do(newReq)
+	return resp, err
+}
+
+// Deals with responding to parse requests, for the purpose of testing queries
+// are only valid when they start with FOR and contain RETURN.
+func (c *explainTestClient) parse(w http.ResponseWriter, query string) {
+	if !strings.HasPrefix(query, "FOR ") {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("{\"exception\":\"Error 1501: syntax error, unexpected identifier near 'FRO u' at position 1:1\"}"))
+		return
+	}
+	if !strings.Contains(query, "RETURN") {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("{\"exception\":\"Error 1501: syntax error, unexpected end of query at position 2:14\"}"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	b, _ := json.Marshal(map[string]interface{}{
+		"parsed":      true,
+		"collections": []string{"users"},
+		"bindVars":    types.QueryBindParams(query),
+	})
+	w.Write(b)
+}
+
+var _ = Suite(&ExplainSuite{})
+
+func (s *ExplainSuite) SetUpSuite(c *C) {
+	cli, err := NewClient(&types.ConnectionParams{}, newExplainTestHttpClient())
+	if err != nil {
+		c.Error("Failed to setup our client for testing.")
+	}
+	s.client = cli
+}
+
+var explainTestParams = &types.CursorQueryParams{
+	Query:    "FOR u IN users FILTER u.age >= @age LIMIT 5 RETURN u",
+	BindVars: map[string]interface{}{"age": 21},
+}
+
+func (s *ExplainSuite) TestExplainQuery(c *C) {
+	res := s.client.ExplainQuery(explainTestParams)
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(res.Cacheable, Equals, true)
+	c.Assert(res.Warnings, DeepEquals, []*types.QueryWarning{{Code: 1562, Message: "division by zero"}})
+	c.Assert(len(res.Plan.Nodes), Equals, 4)
+	c.Assert(res.Plan.EstimatedCost, Equals, 17.5)
+	c.Assert(res.Plan.Rules, DeepEquals, []string{"use-indexes", "remove-filter-covered-by-index"})
+	c.Assert(res.Plan.Collections[0].Name, Equals, "users")
+	index := res.Plan.Nodes[1].Indexes[0]
+	c.Assert(index.Name, Equals, "idx_age")
+	c.Assert(index.Fields, DeepEquals, []string{"age"})
+	c.Assert(index.Selectivity, Equals, 0.4)
+	c.Assert(res.Plan.Nodes[1].Stats, IsNil)
+	c.Assert(res.Plan.String(), Equals, "ReturnNode #5 (cost 17.5, 5 items)\n"+
+		"└─ LimitNode #4 (cost 12.5, 5 items)\n"+
+		"   └─ IndexNode #6 users [persistent index on age] (cost 11.5, 10 items)\n"+
+		"      └─ SingletonNode #1 (cost 1, 1 items)\n"+
+		"Optimizer rules applied: use-indexes, remove-filter-covered-by-index\n")
+	// Invalid queries are reported as usual.
+	res = s.client.ExplainQuery(&types.CursorQueryParams{Query: "FOR u users"})
+	c.Assert(res.Err, Equals, ErrBadRequest)
+	c.Assert(res.Message, Equals, "Error 1501: syntax error, unexpected identifier")
+	c.Assert(res.Plan, IsNil)
+	c.Assert(res.Plan.String(), Equals, "")
+}
+
+func (s *ExplainSuite) TestProfileQuery(c *C) {
+	res := s.client.ProfileQuery(explainTestParams)
+	c.Assert(res.Err, IsNil)
+	var docs []map[string]interface{}
+	c.Assert(json.NewDecoder(res.Documents).Decode(&docs), IsNil)
+	c.Assert(docs[0]["_key"], Equals, "u1")
+	c.Assert(res.ExecutionTime, Equals, 0.003)
+	c.Assert(res.Phases["executing"], Equals, 0.0025)
+	c.Assert(res.Warnings, IsNil)
+	c.Assert(*res.Plan.Nodes[1].Stats, Equals, types.PlanNodeStats{Calls: 1, Items: 1, Runtime: 0.002})
+	// Nodes without statistics are left without them.
+	c.Assert(res.Plan.Nodes[2].Stats, IsNil)
+	c.Assert(strings.Split(res.Plan.String(), "\n")[2], Equals,
+		"   └─ IndexNode #6 users [persistent index on age] (cost 11.5, 10 items, 1 calls, 1 actual items, 0.002s)")
+}
+
+func (s *ExplainSuite) TestParseQuery(c *C) {
+	res := s.client.ParseQuery("FOR u IN @@coll FILTER u.age >= @age RETURN u")
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(res.Collections, DeepEquals, []string{"users"})
+	c.Assert(res.BindVars, DeepEquals, []string{"@coll", "age"})
+	res = s.client.ParseQuery("FOR u IN users\nFILTER u.age")
+	syntaxErr, ok := res.Err.(*QuerySyntaxError)
+	c.Assert(ok, Equals, true)
+	c.Assert(syntaxErr.Line, Equals, 2)
+	c.Assert(syntaxErr.Column, Equals, 14)
+	c.Assert(syntaxErr.Error(), Equals, "Syntax error in query at line 2, column 14: "+
+		"Error 1501: syntax error, unexpected end of query at position 2:14")
+	c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
+	res = s.client.ParseQuery("FRO u IN users RETURN u")
+	c.Assert(res.Err.(*QuerySyntaxError).Column, Equals, 1)
+	c.Assert(res.Collections, IsNil)
+}
+
+func (s *ExplainSuite) TestQueryBindParams(c *C) {
+	query := "FOR u IN @@coll // @commented\n" +
+		"FILTER u.name == \"@quoted\" AND u.`@name` == @name AND u.age > @age AND u.tag == 'it\\'s @x'\n" +
+		"/* @block */ LIMIT @count RETURN { name: @name }"
+	c.Assert(types.QueryBindParams(query), DeepEquals, []string{"@coll", "name", "age", "count"})
+	params := &types.CursorQueryParams{
+		Query:    query,
+		BindVars: map[string]interface{}{"@coll": "users", "name": "a", "age": 21, "count": 10},
+	}
+	c.Assert(params.Validate(), IsNil)
+	delete(params.BindVars, "age")
+	params.BindVars["commented"] = true
+	c.Assert(params.Validate(), ErrorMatches, ".*: missing age; unused commented")
+}
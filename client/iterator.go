@@ -0,0 +1,160 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/freshwebio/go-microfoxx/types"
+)
+
+// CursorIterator provides an iterator to walk through every batch of results of a cursor query.
+// With prefetching enabled the following batches are retrieved in the background while the
+// current batch is processed, in which case Close must be called when the iteration is
+// stopped before reaching the last batch.
+//
+//	it := client.NewCursorIterator(cli, params, &types.CursorIteratorOptions{Prefetch: 2})
+//	defer it.Close()
+//	for it.Next() {
+//		batch := it.Batch()
+//		...
+//	}
+//	if it.Err() != nil {
+//		...
+//	}
+type CursorIterator struct {
+	client   CursorClient
+	params   *types.CursorQueryParams
+	prefetch int
+	batch    *types.CursorQueryResult
+	cursorID string
+	started  bool
+	done     bool
+	err      error
+	// Only used when prefetching.
+	batches   chan *types.CursorQueryResult
+	closed    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewCursorIterator creates a new iterator to walk through the batches of results of the provided
+// cursor query, no requests are made until the first call to Next.
+func NewCursorIterator(client CursorClient, params *types.CursorQueryParams, opts *types.CursorIteratorOptions) *CursorIterator {
+	it := &CursorIterator{
+		client: client,
+		params: params,
+		closed: make(chan struct{}),
+	}
+	if opts != nil && opts.Prefetch > 0 {
+		it.prefetch = opts.Prefetch
+	}
+	return it
+}
+
+// Next retrieves the next batch of results, returning false once every
+// batch has been retrieved, when an error occurs or once the iterator is closed.
+func (it *CursorIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	var batch *types.CursorQueryResult
+	if it.prefetch > 0 {
+		if !it.started {
+			it.started = true
+			it.batches = make(chan *types.CursorQueryResult, it.prefetch)
+			it.wg.Add(1)
+			go it.fetch()
+		}
+		var ok bool
+		select {
+		case batch, ok = <-it.batches:
+		case <-it.closed:
+		}
+		if !ok {
+			it.finish(nil)
+			return false
+		}
+	} else {
+		batch = it.nextBatch()
+		if batch == nil {
+			it.finish(nil)
+			return false
+		}
+	}
+	if batch.Err != nil {
+		it.finish(batch.Err)
+		return false
+	}
+	it.batch = batch
+	return true
+}
+
+// Batch provides the current batch of results.
+func (it *CursorIterator) Batch() *types.CursorQueryResult {
+	return it.batch
+}
+
+// Err provides the error that stopped the iterator, if any.
+func (it *CursorIterator) Err() error {
+	return it.err
+}
+
+// Close deals with stopping the retrieval of batches in the background and waiting
+// for any request in progress to finish, it is safe to call more than once.
+func (it *CursorIterator) Close() error {
+	it.closeOnce.Do(func() {
+		close(it.closed)
+	})
+	it.wg.Wait()
+	it.done = true
+	it.batch = nil
+	return nil
+}
+
+func (it *CursorIterator) finish(err error) {
+	it.done = true
+	it.err = err
+	it.batch = nil
+}
+
+// Deals with retrieving the batch following the current batch,
+// nil is returned once every batch has been retrieved.
+func (it *CursorIterator) nextBatch() *types.CursorQueryResult {
+	if !it.started {
+		it.started = true
+		res := it.client.CursorQuery(it.params)
+		// The cursor identifier is only provided with the first batch.
+		it.cursorID = res.Cursor
+		return res
+	}
+	if !it.batch.HasMore {
+		return nil
+	}
+	return it.client.CursorGetNextBatch(it.cursorID)
+}
+
+// Deals with retrieving every batch in the background and handing them over through
+// the bounded batches channel until the last batch, an error or the iterator is closed.
+func (it *CursorIterator) fetch() {
+	defer it.wg.Done()
+	defer close(it.batches)
+	started := false
+	cursorID := ""
+	for {
+		var res *types.CursorQueryResult
+		if !started {
+			started = true
+			res = it.client.CursorQuery(it.params)
+			cursorID = res.Cursor
+		} else {
+			res = it.client.CursorGetNextBatch(cursorID)
+		}
+		select {
+		case it.batches <- res:
+		case <-it.closed:
+			return
+		}
+		if res.Err != nil || !res.HasMore {
+			return
+		}
+	}
+}
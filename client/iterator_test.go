@@ -0,0 +1,135 @@
+package client_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/freshwebio/go-microfoxx/client"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+type IteratorSuite struct{}
+
+// Provides a cursor of numbered batches of a single result each
+// while counting the requests made for them.
+type batchesTestClient struct {
+	mu       sync.Mutex
+	requests int
+	batches  int
+	// The batch that fails to be retrieved, if any.
+	failAt int
+}
+
+var errBatch = errors.New("The batch could not be retrieved")
+
+func (c *batchesTestClient) CursorQuery(params *types.CursorQueryParams) *types.CursorQueryResult {
+	res := c.batch(1)
+	res.Cursor = "1"
+	return res
+}
+
+func (c *batchesTestClient) CursorGetNextBatch(cursorID string) *types.CursorQueryResult {
+	c.mu.Lock()
+	next := c.requests + 1
+	c.mu.Unlock()
+	return c.batch(next)
+}
+
+func (c *batchesTestClient) batch(n int) *types.CursorQueryResult {
+	c.mu.Lock()
+	c.requests++
+	c.mu.Unlock()
+	if n == c.failAt {
+		return &types.CursorQueryResult{Err: errBatch}
+	}
+	b := new(bytes.Buffer)
+	json.NewEncoder(b).Encode([]string{"batch" + strconv.Itoa(n)})
+	return &types.CursorQueryResult{Documents: b, HasMore: n < c.batches}
+}
+
+func (c *batchesTestClient) requestsMade() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests
+}
+
+var _ = Suite(&IteratorSuite{})
+
+func readBatches(it *CursorIterator) []string {
+	results := make([]string, 0)
+	for it.Next() {
+		var batch []string
+		json.NewDecoder(it.Batch().Documents).Decode(&batch)
+		results = append(results, batch...)
+	}
+	return results
+}
+
+func (s *IteratorSuite) TestSequential(c *C) {
+	cli := &batchesTestClient{batches: 3}
+	it := NewCursorIterator(cli, &types.CursorQueryParams{}, nil)
+	c.Assert(cli.requestsMade(), Equals, 0)
+	c.Assert(it.Next(), Equals, true)
+	// Nothing is requested ahead of the current batch.
+	time.Sleep(10 * time.Millisecond)
+	c.Assert(cli.requestsMade(), Equals, 1)
+	c.Assert(readBatches(it), DeepEquals, []string{"batch2", "batch3"})
+	c.Assert(it.Err(), IsNil)
+	c.Assert(it.Batch(), IsNil)
+	c.Assert(cli.requestsMade(), Equals, 3)
+	c.Assert(it.Close(), IsNil)
+}
+
+func (s *IteratorSuite) TestPrefetch(c *C) {
+	cli := &batchesTestClient{batches: 20}
+	it := NewCursorIterator(cli, &types.CursorQueryParams{}, &types.CursorIteratorOptions{Prefetch: 2})
+	defer it.Close()
+	c.Assert(it.Next(), Equals, true)
+	// The buffer is filled while the first batch is processed and one more batch is
+	// retrieved while waiting for room in the buffer, but no more than that.
+	deadline := time.Now().Add(time.Second)
+	for cli.requestsMade() < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	c.Assert(cli.requestsMade(), Equals, 4)
+	results := readBatches(it)
+	c.Assert(len(results), Equals, 19)
+	c.Assert(results[0], Equals, "batch2")
+	c.Assert(results[18], Equals, "batch20")
+	c.Assert(it.Err(), IsNil)
+	c.Assert(cli.requestsMade(), Equals, 20)
+}
+
+func (s *IteratorSuite) TestPrefetchClose(c *C) {
+	cli := &batchesTestClient{batches: 100}
+	it := NewCursorIterator(cli, &types.CursorQueryParams{}, &types.CursorIteratorOptions{Prefetch: 3})
+	c.Assert(it.Next(), Equals, true)
+	c.Assert(it.Close(), IsNil)
+	requests := cli.requestsMade()
+	c.Assert(requests <= 5, Equals, true)
+	// Nothing more is retrieved once closed.
+	c.Assert(it.Next(), Equals, false)
+	c.Assert(it.Err(), IsNil)
+	time.Sleep(10 * time.Millisecond)
+	c.Assert(cli.requestsMade(), Equals, requests)
+	c.Assert(it.Close(), IsNil)
+}
+
+func (s *IteratorSuite) TestErrors(c *C) {
+	for _, opts := range []*types.CursorIteratorOptions{nil, {Prefetch: 4}} {
+		cli := &batchesTestClient{batches: 10, failAt: 3}
+		it := NewCursorIterator(cli, &types.CursorQueryParams{}, opts)
+		// The batches before the failure are still provided.
+		c.Assert(readBatches(it), DeepEquals, []string{"batch1", "batch2"})
+		c.Assert(it.Err(), Equals, errBatch)
+		c.Assert(it.Next(), Equals, false)
+		c.Assert(it.Close(), IsNil)
+		c.Assert(cli.requestsMade(), Equals, 3)
+	}
+}
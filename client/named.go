@@ -0,0 +1,102 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/freshwebio/go-microfoxx/registry"
+	"github.com/freshwebio/go-microfoxx/types"
+)
+
+// NamedQueryClient provides the functionality to run the queries
+// of a registry by name.
+type NamedQueryClient interface {
+	Named(string, map[string]interface{}) *types.CursorQueryResult
+	NamedModifyingQuery(string, map[string]interface{}) *types.DocumentsOpResult
+}
+
+// WithRegistry provides a copy of the client which runs named queries from the provided registry,
+// the copy shares the client's session so refreshing either of them refreshes both.
+func (c *clientImpl) WithRegistry(reg *registry.Registry) Client {
+	cli := *c
+	cli.registry = reg
+	return &cli
+}
+
+// Named deals with running the read query registered with the provided name through a cursor
+// with the batch size set for the query, the registry's metrics are notified once the first
+// batch has been retrieved. Modifying queries must be run with NamedModifyingQuery.
+func (c *clientImpl) Named(name string, bindVars map[string]interface{}) *types.CursorQueryResult {
+	query, err := c.namedQuery(name)
+	if err != nil {
+		return &types.CursorQueryResult{Err: err}
+	}
+	if query.Kind != "" {
+		return &types.CursorQueryResult{Err: fmt.Errorf("%w: %s is a modifying query", ErrInvalidQueryKind, name)}
+	}
+	start := time.Now()
+	res := c.CursorQuery(&types.CursorQueryParams{
+		Query:     query.Query,
+		BindVars:  bindVars,
+		BatchSize: query.BatchSize,
+	})
+	c.recordNamedQuery(name, start, res.StatusCode, res.Err)
+	return res
+}
+
+// NamedModifyingQuery deals with running the modifying query registered with the provided name,
+// the collections declared with the @write annotations of the query are its write collections.
+func (c *clientImpl) NamedModifyingQuery(name string, bindVars map[string]interface{}) *types.DocumentsOpResult {
+	query, err := c.namedQuery(name)
+	if err != nil {
+		return &types.DocumentsOpResult{Err: err}
+	}
+	if query.Kind == "" {
+		return &types.DocumentsOpResult{Err: fmt.Errorf("%w: %s is a read query", ErrInvalidQueryKind, name)}
+	}
+	writeColls, err := namedWriteCollections(query, bindVars)
+	if err != nil {
+		return &types.DocumentsOpResult{Err: err}
+	}
+	params := &types.ModifyingQueryParams{
+		Query:            query.Query,
+		BindVars:         bindVars,
+		WriteCollections: writeColls,
+	}
+	start := time.Now()
+	res := c.ModifyingQuery(query.Kind, params)
+	c.recordNamedQuery(name, start, res.StatusCode, res.Err)
+	return res
+}
+
+func (c *clientImpl) namedQuery(name string) (*registry.Query, error) {
+	if c.registry == nil {
+		return nil, fmt.Errorf("%w: %s, the client has no registry", registry.ErrUnknownQuery, name)
+	}
+	return c.registry.Query(name)
+}
+
+// Deals with resolving the write collections of a named query, collections
+// declared by a bind parameter are taken from the bind variables.
+func namedWriteCollections(query *registry.Query, bindVars map[string]interface{}) ([]string, error) {
+	colls := make([]string, 0, len(query.WriteCollections))
+	for _, coll := range query.WriteCollections {
+		if strings.HasPrefix(coll, "@@") {
+			name, ok := bindVars[strings.TrimPrefix(coll, "@")].(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: missing %s for the write collection of %s",
+					types.ErrBindVars, strings.TrimPrefix(coll, "@"), query.Name)
+			}
+			coll = name
+		}
+		colls = append(colls, coll)
+	}
+	return colls, nil
+}
+
+func (c *clientImpl) recordNamedQuery(name string, start time.Time, statusCode int, err error) {
+	if metrics := c.registry.Metrics(); metrics != nil {
+		metrics.QueryExecuted(name, time.Since(start), statusCode, err)
+	}
+}
@@ -0,0 +1,173 @@
+package client_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing/fstest"
+	"time"
+
+	. "github.com/freshwebio/go-microfoxx/client"
+	"github.com/freshwebio/go-microfoxx/registry"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+type NamedSuite struct {
+	base    Client
+	client  Client
+	tc      *namedTestClient
+	metrics *testMetrics
+}
+
+type namedTestClient struct {
+	dummySessionClient
+	batchSize int
+	params    types.ModifyingQueryParams
+	// The amount of sessions created and the session of the last request.
+	logins int
+	sid    string
+}
+
+// Deals with creating a new session for every login so requests
+// can be matched to the session they were made with.
+func (c *namedTestClient) Post(url string, bodyType string, body io.Reader) (resp *http.Response, err error) {
+	c.logins++
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader("{\"sid\":\"s" + strconv.Itoa(c.logins) + "\",\"uid\":\"6789\"}")),
+	}, nil
+}
+
+type testMetrics struct {
+	names       []string
+	statusCodes []int
+	errs        []error
+}
+
+func (m *testMetrics) QueryExecuted(name string, duration time.Duration, statusCode int, err error) {
+	m.names = append(m.names, name)
+	m.statusCodes = append(m.statusCodes, statusCode)
+	m.errs = append(m.errs, err)
+}
+
+// Deals with preparing a response for the cursor and insert requests made by named queries.
+func (c *namedTestClient) Do(req *http.Request) (resp *http.Response, err error) {
+	c.sid = req.Header.Get("X-Session-Id")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		switch strings.TrimPrefix(r.URL.Path, "/_db//microfoxx") {
+		case "/cursor":
+			var params types.CursorQueryParams
+			json.NewDecoder(r.Body).Decode(&params)
+			c.batchSize = params.BatchSize
+			if params.BindVars["since"] == "never" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("{\"exception\":\"Error 1504: invalid date\"}"))
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{\"results\":[{\"_key\":\"u1\"}],\"hasMore\":false}"))
+		case "/insert", "/update":
+			json.NewDecoder(r.Body).Decode(&c.params)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{\"docs\":[{\"_key\":\"o1\"}],\"events\":[{\"op\":\"insert\",\"key\":\"o1\"}]}"))
+		}
+	}))
+	defer server.Close()
+	newReq, _ := http.NewRequest(req.Method, server.URL+req.URL.Path, req.Body)
+	resp, err = http.DefaultClient.Do(newReq)
+	return resp, err
+}
+
+var _ = Suite(&NamedSuite{})
+
+func (s *NamedSuite) SetUpTest(c *C) {
+	s.tc = &namedTestClient{}
+	cli, err := NewClient(&types.ConnectionParams{}, s.tc)
+	if err != nil {
+		c.Error("Failed to setup our client for testing.")
+	}
+	reg, err := registry.Load(fstest.MapFS{
+		"queries/find_active_users.aql": {Data: []byte("// @batchSize 50\nFOR u IN users FILTER u.lastSeen >= @since RETURN u")},
+		"queries/add_order.aql":         {Data: []byte("// @kind insert\n// @write @@orders\nINSERT @order INTO @@orders")},
+		"queries/flag_users.aql": {Data: []byte("// @kind update\n// @write users\n" +
+			"FOR u IN users UPDATE u WITH { flagged: u.country IN @blocked } IN users")},
+	}, "queries")
+	c.Assert(err, IsNil)
+	s.metrics = &testMetrics{}
+	reg.SetMetrics(s.metrics)
+	s.base = cli
+	s.client = cli.WithRegistry(reg)
+}
+
+func (s *NamedSuite) TestNamed(c *C) {
+	res := s.client.Named("find_active_users", map[string]interface{}{"since": "2024-01-01"})
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusCreated)
+	c.Assert(s.tc.batchSize, Equals, 50)
+	var docs []map[string]interface{}
+	c.Assert(json.NewDecoder(res.Documents).Decode(&docs), IsNil)
+	c.Assert(docs[0]["_key"], Equals, "u1")
+	res = s.client.Named("find_active_users", map[string]interface{}{"since": "never"})
+	c.Assert(res.Err, Equals, ErrBadRequest)
+	// Missing bind variables are caught before the request is made.
+	res = s.client.Named("find_active_users", nil)
+	c.Assert(errors.Is(res.Err, types.ErrBindVars), Equals, true)
+	c.Assert(s.metrics.names, DeepEquals, []string{"find_active_users", "find_active_users", "find_active_users"})
+	c.Assert(s.metrics.statusCodes, DeepEquals, []int{http.StatusCreated, http.StatusBadRequest, 0})
+	c.Assert(s.metrics.errs[0], IsNil)
+	c.Assert(s.metrics.errs[1], Equals, ErrBadRequest)
+}
+
+func (s *NamedSuite) TestNamedModifyingQuery(c *C) {
+	res := s.client.NamedModifyingQuery("add_order", map[string]interface{}{
+		"order":   map[string]interface{}{"item": "a"},
+		"@orders": "orders",
+	})
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.DecodedEvents[0].Key, Equals, "o1")
+	c.Assert(s.tc.params.WriteCollections, DeepEquals, []string{"orders"})
+	c.Assert(s.tc.params.Query, Equals, "// @kind insert\n// @write @@orders\nINSERT @order INTO @@orders")
+	c.Assert(s.metrics.names, DeepEquals, []string{"add_order"})
+	// Queries must be run according to their kind.
+	c.Assert(errors.Is(s.client.Named("add_order", nil).Err, ErrInvalidQueryKind), Equals, true)
+	c.Assert(errors.Is(s.client.NamedModifyingQuery("find_active_users", nil).Err, ErrInvalidQueryKind), Equals, true)
+	c.Assert(len(s.metrics.names), Equals, 1)
+	// Collections provided through bind parameters need their bind variable.
+	res = s.client.NamedModifyingQuery("add_order", map[string]interface{}{"order": map[string]interface{}{}})
+	c.Assert(errors.Is(res.Err, types.ErrBindVars), Equals, true)
+	c.Assert(len(s.metrics.names), Equals, 1)
+}
+
+func (s *NamedSuite) TestNamedModifyingQueryWriteCollections(c *C) {
+	// Only the declared collections are written to, the IN operator doesn't add any.
+	res := s.client.NamedModifyingQuery("flag_users", map[string]interface{}{"blocked": []string{"xx"}})
+	c.Assert(res.Err, IsNil)
+	c.Assert(s.tc.params.WriteCollections, DeepEquals, []string{"users"})
+	c.Assert(s.tc.params.WriteCollection, Equals, "users")
+}
+
+func (s *NamedSuite) TestNamedAfterRefresh(c *C) {
+	c.Assert(s.client.Named("find_active_users", map[string]interface{}{"since": "2024-01-01"}).Err, IsNil)
+	c.Assert(s.tc.sid, Equals, "s1")
+	// Refreshing the client the registry client was made from refreshes its session too.
+	c.Assert(s.base.Refresh(), IsNil)
+	c.Assert(s.client.Named("find_active_users", map[string]interface{}{"since": "2024-01-01"}).Err, IsNil)
+	c.Assert(s.tc.sid, Equals, "s2")
+}
+
+func (s *NamedSuite) TestUnknownQuery(c *C) {
+	res := s.client.Named("find_inactive_users", nil)
+	c.Assert(errors.Is(res.Err, registry.ErrUnknownQuery), Equals, true)
+	// Clients without a registry have no named queries.
+	cli, _ := NewClient(&types.ConnectionParams{}, s.tc)
+	res = cli.Named("find_active_users", nil)
+	c.Assert(errors.Is(res.Err, registry.ErrUnknownQuery), Equals, true)
+	c.Assert(res.Err, ErrorMatches, ".*: find_active_users, the client has no registry")
+}
@@ -0,0 +1,322 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/freshwebio/go-microfoxx/types"
+)
+
+const (
+	// DefaultPageSize is the amount of documents retrieved for each page
+	// when no limit count is provided in the retrieval parameters.
+	DefaultPageSize = 100
+)
+
+// PageClient provides the functionality to walk through the documents
+// of a collection with keyset pagination.
+type PageClient interface {
+	GetDocsPage(string, *types.DocumentRetrievalParams) *types.Page
+	NextPage(string) *types.Page
+}
+
+// The state encoded into the opaque continuation token for a page,
+// After holds the sort key values of the last document in the previous page.
+type pageToken struct {
+	Coll   string             `json:"c"`
+	Fields map[string]string  `json:"fl,omitempty"`
+	Filter *types.Filter      `json:"f,omitempty"`
+	Sort   []*types.SortField `json:"s"`
+	Return []string           `json:"r,omitempty"`
+	Size   int                `json:"n"`
+	After  []interface{}      `json:"a"`
+}
+
+// GetDocsPage retrieves the first page of documents for the provided retrieval parameters.
+// Rather than skipping an offset on every request the following pages are retrieved
+// through NextPage with a token derived from the sort keys of the last document, so each
+// page stays fast and consistent under concurrent writes. The _key attribute is always used
+// as the final sort field to ensure a stable order. LimitCount sets the page size and
+// LimitOffset can't be used. When a projection is provided the sort fields are added to it
+// as their values are needed to build the continuation token.
+func (c *clientImpl) GetDocsPage(coll string, params *types.DocumentRetrievalParams) *types.Page {
+	if params.LimitOffset > 0 {
+		return &types.Page{Err: ErrPageOffset}
+	}
+	if len(params.Sort) > 0 && len(params.SortFields) > 0 {
+		return &types.Page{Err: fmt.Errorf("%w: SortFields and Sort can't be used together", types.ErrInvalidSort)}
+	}
+	token := &pageToken{
+		Coll:   coll,
+		Fields: params.Fields,
+		Filter: params.Filter,
+		Return: params.Return,
+		Size:   params.LimitCount,
+	}
+	if token.Size <= 0 {
+		token.Size = DefaultPageSize
+	}
+	if len(params.Sort) > 0 {
+		token.Sort = append(token.Sort, params.Sort...)
+	} else {
+		for _, field := range params.SortFields {
+			token.Sort = append(token.Sort, &types.SortField{Field: field, Direction: types.SortDirection(params.SortOrder)})
+		}
+	}
+	hasKey := false
+	for _, sortField := range token.Sort {
+		if sortField != nil && sortField.Field == keyField {
+			hasKey = true
+		}
+	}
+	if !hasKey {
+		token.Sort = append(token.Sort, types.Asc(keyField))
+	}
+	if len(token.Return) > 0 {
+		token.Return = withSortFields(token.Return, token.Sort)
+	}
+	return c.getPage(token)
+}
+
+// NextPage retrieves the page of documents following the page
+// the provided continuation token was returned with.
+func (c *clientImpl) NextPage(token string) *types.Page {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return &types.Page{Err: ErrInvalidPageToken}
+	}
+	var pt pageToken
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	err = decoder.Decode(&pt)
+	if err != nil || pt.Coll == "" || pt.Size <= 0 || len(pt.Sort) == 0 || len(pt.After) != len(pt.Sort) {
+		return &types.Page{Err: ErrInvalidPageToken}
+	}
+	return c.getPage(&pt)
+}
+
+// Deals with retrieving the page following the sort key values in the token,
+// one more document than the page size is requested to find out whether
+// there is another page without an extra request.
+func (c *clientImpl) getPage(token *pageToken) *types.Page {
+	filter := token.Filter
+	if len(token.After) > 0 {
+		keyset := keysetFilter(token.Sort, token.After)
+		if keyset == nil {
+			// Nothing can come after the previous page.
+			return emptyPage()
+		}
+		if filter != nil {
+			filter = types.And(filter, keyset)
+		} else {
+			filter = keyset
+		}
+	}
+	res := c.GetDocs(token.Coll, &types.DocumentRetrievalParams{
+		Fields:     token.Fields,
+		Filter:     filter,
+		Sort:       token.Sort,
+		Return:     token.Return,
+		LimitCount: token.Size + 1,
+	})
+	if res.Err != nil {
+		// The service responds with not found when there are no documents
+		// which simply means the end has been reached for any page after the first.
+		if res.Err == ErrNotFound && len(token.After) > 0 {
+			return emptyPage()
+		}
+		return &types.Page{
+			Err:        res.Err,
+			StatusCode: res.StatusCode,
+			Message:    res.Message,
+		}
+	}
+	var docs []map[string]interface{}
+	decoder := json.NewDecoder(res.Documents)
+	decoder.UseNumber()
+	err := decoder.Decode(&docs)
+	if err != nil {
+		return &types.Page{Err: err}
+	}
+	page := types.Page{StatusCode: res.StatusCode}
+	if len(docs) > token.Size {
+		docs = docs[:token.Size]
+		next := *token
+		next.After = make([]interface{}, len(token.Sort))
+		for i, sortField := range token.Sort {
+			next.After[i] = lookupField(docs[len(docs)-1], sortField.Field)
+		}
+		b, err := json.Marshal(next)
+		if err != nil {
+			return &types.Page{Err: err}
+		}
+		page.NextToken = base64.RawURLEncoding.EncodeToString(b)
+	}
+	bd := new(bytes.Buffer)
+	err = json.NewEncoder(bd).Encode(docs)
+	if err != nil {
+		return &types.Page{Err: err}
+	}
+	page.Documents = bd
+	page.Count = len(docs)
+	return &page
+}
+
+func emptyPage() *types.Page {
+	return &types.Page{
+		Documents: strings.NewReader("[]"),
+	}
+}
+
+// Builds the filter matching every document that sorts after the provided
+// sort key values, for sort fields a, b and values x, y this is
+// (a after x) OR (a == x AND b after y).
+// Returns nil when no document can sort after the values.
+func keysetFilter(sort []*types.SortField, after []interface{}) *types.Filter {
+	alternatives := make([]*types.Filter, 0, len(sort))
+	for i, sortField := range sort {
+		next := afterFilter(sortField, after[i])
+		if next == nil {
+			continue
+		}
+		conditions := make([]*types.Filter, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, types.Eq(sort[j].Field, after[j]))
+		}
+		conditions = append(conditions, next)
+		if len(conditions) == 1 {
+			alternatives = append(alternatives, next)
+		} else {
+			alternatives = append(alternatives, types.And(conditions...))
+		}
+	}
+	if len(alternatives) == 0 {
+		return nil
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+	return types.Or(alternatives...)
+}
+
+// Builds the filter matching values that sort strictly after the provided value.
+// Null sorts before every other value so in ascending order anything that isn't null
+// comes after it and in descending order nothing does.
+func afterFilter(sortField *types.SortField, value interface{}) *types.Filter {
+	direction, _ := types.ParseSortDirection(string(sortField.Direction))
+	if direction == types.SortDesc {
+		if value == nil {
+			return nil
+		}
+		return types.Lt(sortField.Field, value)
+	}
+	if value == nil {
+		return types.Exists(sortField.Field, true)
+	}
+	return types.Gt(sortField.Field, value)
+}
+
+// Adds any of the sort fields missing from the provided projection.
+func withSortFields(fields []string, sort []*types.SortField) []string {
+	projection := append([]string{}, fields...)
+	for _, sortField := range sort {
+		if sortField == nil {
+			continue
+		}
+		found := false
+		for _, field := range projection {
+			if field == sortField.Field {
+				found = true
+			}
+		}
+		if !found {
+			projection = append(projection, sortField.Field)
+		}
+	}
+	return projection
+}
+
+// Retrieves the value of the attribute at the provided dot separated path,
+// nil is returned for missing attributes.
+func lookupField(doc map[string]interface{}, path string) interface{} {
+	var current interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = obj[part]
+	}
+	return current
+}
+
+// Pager provides an iterator to walk through every page of documents
+// for a set of retrieval parameters.
+//
+//	pager := client.NewPager(cli, "users", params)
+//	for pager.Next() {
+//		page := pager.Page()
+//		...
+//	}
+//	if pager.Err() != nil {
+//		...
+//	}
+type Pager struct {
+	client  PageClient
+	coll    string
+	params  *types.DocumentRetrievalParams
+	page    *types.Page
+	started bool
+	err     error
+}
+
+// NewPager creates a new pager to walk through the documents in the provided collection.
+func NewPager(client PageClient, coll string, params *types.DocumentRetrievalParams) *Pager {
+	return &Pager{
+		client: client,
+		coll:   coll,
+		params: params,
+	}
+}
+
+// Next retrieves the next page of documents, returning false once
+// every page has been retrieved or when an error occurs.
+func (p *Pager) Next() bool {
+	if p.err != nil {
+		return false
+	}
+	var page *types.Page
+	if !p.started {
+		p.started = true
+		page = p.client.GetDocsPage(p.coll, p.params)
+		// An empty collection is simply the end of the walk.
+		if page.Err == ErrNotFound {
+			p.page = nil
+			return false
+		}
+	} else if p.page != nil && p.page.NextToken != "" {
+		page = p.client.NextPage(p.page.NextToken)
+	} else {
+		return false
+	}
+	if page.Err != nil {
+		p.err = page.Err
+		p.page = nil
+		return false
+	}
+	p.page = page
+	return page.Count > 0
+}
+
+// Page provides the page of documents retrieved by the last call to Next.
+func (p *Pager) Page() *types.Page {
+	return p.page
+}
+
+// Err provides the error that stopped the pager, if any.
+func (p *Pager) Err() error {
+	return p.err
+}
@@ -0,0 +1,309 @@
+package client_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+
+	. "github.com/freshwebio/go-microfoxx/client"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+type PagesSuite struct {
+	client Client
+}
+
+type pagesTestClient struct {
+	dummySessionClient
+	documents []map[string]interface{}
+}
+
+func newPagesTestHttpClient() WebClient {
+	tc := &pagesTestClient{}
+	// Ranks are repeated so the _key tie breaker is needed for a stable order
+	// and every fifth document has no rank at all.
+	for i := 1; i <= 23; i++ {
+		doc := map[string]interface{}{
+			"_key":  fmt.Sprintf("k%02d", i),
+			"stats": map[string]interface{}{},
+		}
+		if i%5 != 0 {
+			doc["stats"].(map[string]interface{})["rank"] = float64(i % 4)
+		}
+		tc.documents = append(tc.documents, doc)
+	}
+	return tc
+}
+
+// Deals with preparing a response for paged document requests by applying the
+// filter, sort and limit parameters to the test documents.
+func (c *pagesTestClient) Do(req *http.Request) (resp *http.Response, err error) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/_db//microfoxx")
+		if path != "/test" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte("{\"exception\":\"Error 2016: that collection doesn't exist\"}"))
+			return
+		}
+		c.getDocs(w, r)
+	}))
+	defer server.Close()
+	newReq, _ := http.NewRequest(req.Method, server.URL+req.URL.Path, req.Body)
+	newReq.URL.RawQuery = req.URL.RawQuery
+	resp, err = http.DefaultClient.Do(newReq)
+	return resp, err
+}
+
+func (c *pagesTestClient) getDocs(w http.ResponseWriter, req *http.Request) {
+	var filter *types.Filter
+	if filterParam := req.URL.Query().Get("filter"); filterParam != "" {
+		filter = &types.Filter{}
+		json.Unmarshal([]byte(filterParam), filter)
+	}
+	docs := make([]map[string]interface{}, 0)
+	for _, doc := range c.documents {
+		if filter == nil || matchesFilter(doc, filter) {
+			docs = append(docs, doc)
+		}
+	}
+	sortFields := strings.Split(req.URL.Query().Get("sort"), ",")
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, sortField := range sortFields {
+			parts := strings.Split(sortField, "::")
+			cmp := compareValues(fieldValue(docs[i], parts[0]), fieldValue(docs[j], parts[0]))
+			if cmp != 0 {
+				return (cmp < 0) == (parts[1] == "ASC")
+			}
+		}
+		return false
+	})
+	limit := strings.Split(req.URL.Query().Get("limit"), ",")
+	count, _ := strconv.Atoi(limit[1])
+	if count < len(docs) {
+		docs = docs[:count]
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if len(docs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("{\"exception\":\"Error 2016: No documents found\"}"))
+		return
+	}
+	if returnParam := req.URL.Query().Get("return"); returnParam != "" {
+		projected := make([]map[string]interface{}, 0, len(docs))
+		for _, doc := range docs {
+			projection := make(map[string]interface{})
+			for _, field := range strings.Split(returnParam, ",") {
+				// Nested attributes keep their structure in the projection.
+				parts := strings.Split(field, ".")
+				current := projection
+				for _, part := range parts[:len(parts)-1] {
+					if _, exists := current[part]; !exists {
+						current[part] = make(map[string]interface{})
+					}
+					current = current[part].(map[string]interface{})
+				}
+				current[parts[len(parts)-1]] = fieldValue(doc, field)
+			}
+			projected = append(projected, projection)
+		}
+		docs = projected
+	}
+	w.WriteHeader(http.StatusOK)
+	b, _ := json.Marshal(docs)
+	w.Write(b)
+}
+
+func fieldValue(doc map[string]interface{}, path string) interface{} {
+	var current interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = obj[part]
+	}
+	return current
+}
+
+// Compares values the way AQL does for the types used in the tests,
+// null sorts before numbers which sort before strings.
+func compareValues(a interface{}, b interface{}) int {
+	rank := func(v interface{}) int {
+		switch v.(type) {
+		case nil:
+			return 0
+		case float64:
+			return 1
+		}
+		return 2
+	}
+	if rank(a) != rank(b) {
+		return rank(a) - rank(b)
+	}
+	switch av := a.(type) {
+	case float64:
+		bv := b.(float64)
+		if av < bv {
+			return -1
+		} else if av > bv {
+			return 1
+		}
+	case string:
+		return strings.Compare(av, b.(string))
+	}
+	return 0
+}
+
+func matchesFilter(doc map[string]interface{}, filter *types.Filter) bool {
+	switch filter.Op {
+	case types.FilterAnd:
+		for _, sub := range filter.Filters {
+			if !matchesFilter(doc, sub) {
+				return false
+			}
+		}
+		return true
+	case types.FilterOr:
+		for _, sub := range filter.Filters {
+			if matchesFilter(doc, sub) {
+				return true
+			}
+		}
+		return false
+	case types.FilterExists:
+		return (fieldValue(doc, filter.Field) != nil) == filter.Value.(bool)
+	}
+	cmp := compareValues(fieldValue(doc, filter.Field), filter.Value)
+	switch filter.Op {
+	case types.FilterEq:
+		return cmp == 0
+	case types.FilterGt:
+		return cmp > 0
+	case types.FilterLt:
+		return cmp < 0
+	}
+	return false
+}
+
+var _ = Suite(&PagesSuite{})
+
+func (s *PagesSuite) SetUpSuite(c *C) {
+	cli, err := NewClient(&types.ConnectionParams{}, newPagesTestHttpClient())
+	if err != nil {
+		c.Error("Failed to setup our client for testing.")
+	}
+	s.client = cli
+}
+
+func (s *PagesSuite) TestGetDocsPage(c *C) {
+	params := &types.DocumentRetrievalParams{
+		Sort:       []*types.SortField{types.Desc("stats.rank")},
+		LimitCount: 10,
+	}
+	page := s.client.GetDocsPage("test", params)
+	c.Assert(page.Err, Equals, nil)
+	c.Assert(page.StatusCode, Equals, http.StatusOK)
+	c.Assert(page.Count, Equals, 10)
+	c.Assert(page.NextToken, Not(Equals), "")
+	var docs []map[string]interface{}
+	json.NewDecoder(page.Documents).Decode(&docs)
+	c.Assert(len(docs), Equals, 10)
+	// Documents with the highest rank come first ordered by key.
+	c.Assert(docs[0]["_key"], Equals, "k03")
+	c.Assert(docs[1]["_key"], Equals, "k07")
+	// Now retrieve the remaining pages, the last one holds the documents without a rank.
+	page = s.client.NextPage(page.NextToken)
+	c.Assert(page.Err, Equals, nil)
+	c.Assert(page.Count, Equals, 10)
+	page = s.client.NextPage(page.NextToken)
+	c.Assert(page.Err, Equals, nil)
+	c.Assert(page.Count, Equals, 3)
+	c.Assert(page.NextToken, Equals, "")
+	docs = nil
+	json.NewDecoder(page.Documents).Decode(&docs)
+	c.Assert(docs[0]["_key"], Equals, "k10")
+	c.Assert(docs[2]["_key"], Equals, "k20")
+	// Ensure invalid tokens and offsets are rejected.
+	page = s.client.NextPage("not a token")
+	c.Assert(page.Err, Equals, ErrInvalidPageToken)
+	page = s.client.GetDocsPage("test", &types.DocumentRetrievalParams{LimitOffset: 10, LimitCount: 10})
+	c.Assert(page.Err, Equals, ErrPageOffset)
+	// Both sort styles can't be combined.
+	page = s.client.GetDocsPage("test", &types.DocumentRetrievalParams{
+		Sort:       []*types.SortField{types.Desc("height")},
+		SortFields: []string{"name"},
+		SortOrder:  "ASC",
+	})
+	c.Assert(errors.Is(page.Err, types.ErrInvalidSort), Equals, true)
+	c.Assert(page.StatusCode, Equals, 0)
+	// Errors from the service are passed through for the first page.
+	page = s.client.GetDocsPage("cars", params)
+	c.Assert(page.Err, Equals, ErrBadRequest)
+	c.Assert(page.StatusCode, Equals, http.StatusBadRequest)
+	c.Assert(page.Message, Equals, "Error 2016: that collection doesn't exist")
+}
+
+func (s *PagesSuite) TestPager(c *C) {
+	// Walk the whole collection in ascending order so the documents
+	// without a rank come first and ensure every document is seen exactly once.
+	pager := NewPager(s.client, "test", &types.DocumentRetrievalParams{
+		Sort:       []*types.SortField{types.Asc("stats.rank")},
+		LimitCount: 4,
+	})
+	seen := make(map[string]bool)
+	pages := 0
+	var last float64 = -1
+	for pager.Next() {
+		pages++
+		var docs []map[string]interface{}
+		json.NewDecoder(pager.Page().Documents).Decode(&docs)
+		for _, doc := range docs {
+			key := doc["_key"].(string)
+			c.Assert(seen[key], Equals, false)
+			seen[key] = true
+			if rank, ok := doc["stats"].(map[string]interface{})["rank"].(float64); ok {
+				c.Assert(rank >= last, Equals, true)
+				last = rank
+			}
+		}
+	}
+	c.Assert(pager.Err(), Equals, nil)
+	c.Assert(len(seen), Equals, 23)
+	c.Assert(pages, Equals, 6)
+	// A pager for a filter matching nothing simply ends without an error.
+	pager = NewPager(s.client, "test", &types.DocumentRetrievalParams{
+		Filter: types.Gt("stats.rank", 10),
+	})
+	c.Assert(pager.Next(), Equals, false)
+	c.Assert(pager.Err(), Equals, nil)
+	// Errors stop the pager and are reported.
+	pager = NewPager(s.client, "cars", &types.DocumentRetrievalParams{})
+	c.Assert(pager.Next(), Equals, false)
+	c.Assert(pager.Err(), Equals, ErrBadRequest)
+}
+
+func (s *PagesSuite) TestGetDocsPageWithProjection(c *C) {
+	// The sort fields are needed for the continuation token so they
+	// must be included in the projection.
+	page := s.client.GetDocsPage("test", &types.DocumentRetrievalParams{
+		Sort:       []*types.SortField{types.Desc("stats.rank")},
+		Return:     []string{"_key"},
+		LimitCount: 20,
+	})
+	c.Assert(page.Err, Equals, nil)
+	var docs []map[string]interface{}
+	json.NewDecoder(page.Documents).Decode(&docs)
+	c.Assert(len(docs), Equals, 20)
+	c.Assert(len(docs[0]), Equals, 2)
+	c.Assert(docs[0]["stats"].(map[string]interface{})["rank"], Equals, float64(3))
+	page = s.client.NextPage(page.NextToken)
+	c.Assert(page.Err, Equals, nil)
+	c.Assert(page.Count, Equals, 3)
+}
@@ -11,79 +11,69 @@ import (
 // QueryClient provides the functionality for running
 // data modifying queries on the data store service.
 type QueryClient interface {
+	ModifyingQuery(kind types.QueryKind, params *types.ModifyingQueryParams) *types.DocumentsOpResult
 	InsertQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult
 	UpdateQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult
+	ReplaceQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult
 	RemoveQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult
+	UpsertQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult
 }
 
 // InsertQuery sends the provided AQL query and relevant transaction and AQL query bind variables
 // settings and the foxx service then executes the query and returns the newly inserted documents
 // and all the events for each insert operation.
 func (c *clientImpl) InsertQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult {
-	b := new(bytes.Buffer)
-	err := json.NewEncoder(b).Encode(params)
-	if err != nil {
+	return c.ModifyingQuery(types.QueryInsert, params)
+}
+
+// UpdateQuery deals with sending an AQL query to the foxx service which updates existing
+// documents in the Arango data store.
+func (c *clientImpl) UpdateQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult {
+	return c.ModifyingQuery(types.QueryUpdate, params)
+}
+
+// ReplaceQuery deals with sending an AQL query to the foxx service which replaces existing
+// documents in the Arango data store.
+func (c *clientImpl) ReplaceQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult {
+	return c.ModifyingQuery(types.QueryReplace, params)
+}
+
+// RemoveQuery deals with executing the provided removal AQL query through the
+// foxx service endpoint and returns a result with all the removed documents and each removal
+// operation event.
+func (c *clientImpl) RemoveQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult {
+	return c.ModifyingQuery(types.QueryRemove, params)
+}
+
+// UpsertQuery deals with sending an AQL UPSERT query to the foxx service which updates the
+// documents matching its search expression and inserts them when no match exists.
+func (c *clientImpl) UpsertQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult {
+	return c.ModifyingQuery(types.QueryUpsert, params)
+}
+
+// ModifyingQuery deals with sending an AQL query which modifies documents to the foxx service
+// endpoint for the provided kind of query, the result provides the documents affected by
+// the query and the events for each of the operations carried out.
+func (c *clientImpl) ModifyingQuery(kind types.QueryKind, params *types.ModifyingQueryParams) *types.DocumentsOpResult {
+	if !kind.Valid() {
 		return &types.DocumentsOpResult{
-			Err: err,
+			Err: ErrInvalidQueryKind,
 		}
 	}
-	req := c.prepareRequest("POST", "/insert", nil, b)
-	resp, err := c.httpClient.Do(req)
+	params, err := prepareModifyingQueryParams(params)
 	if err != nil {
 		return &types.DocumentsOpResult{
 			Err: err,
 		}
 	}
-	if resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusOK {
-		docOpRes := &types.DocumentsOpResult{}
-		docOpRes.StatusCode = resp.StatusCode
-		var intermediary = struct {
-			Events    []map[string]interface{} `json:"events"`
-			Documents []map[string]interface{} `json:"docs"`
-		}{}
-		err = json.NewDecoder(resp.Body).Decode(&intermediary)
-		if err != nil {
-			return &types.DocumentsOpResult{
-				Err: err,
-			}
-		}
-		bd := new(bytes.Buffer)
-		err = json.NewEncoder(bd).Encode(intermediary.Documents)
-		if err != nil {
-			return &types.DocumentsOpResult{
-				Err: err,
-			}
-		}
-		docOpRes.Documents = bd
-		be := new(bytes.Buffer)
-		err = json.NewEncoder(be).Encode(intermediary.Events)
-		if err != nil {
-			return &types.DocumentsOpResult{
-				Err: err,
-			}
-		}
-		docOpRes.Events = be
-		return docOpRes
-	}
-	msg, err := prepareExceptionResponse(resp)
-	return &types.DocumentsOpResult{
-		Err:        err,
-		Message:    msg,
-		StatusCode: resp.StatusCode,
-	}
-}
-
-// UpdateQuery deals with sending an AQL query to the foxx service which updates existing
-// documents in the Arango data store.
-func (c *clientImpl) UpdateQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult {
 	b := new(bytes.Buffer)
-	err := json.NewEncoder(b).Encode(params)
+	err = json.NewEncoder(b).Encode(params)
 	if err != nil {
 		return &types.DocumentsOpResult{
 			Err: err,
 		}
 	}
-	req := c.prepareRequest("POST", "/update", nil, b)
+	req := c.prepareRequest("POST", "/"+string(kind), nil, b)
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return &types.DocumentsOpResult{
@@ -91,35 +81,7 @@ func (c *clientImpl) UpdateQuery(params *types.ModifyingQueryParams) *types.Docu
 		}
 	}
 	if resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusOK {
-		docOpRes := &types.DocumentsOpResult{}
-		docOpRes.StatusCode = resp.StatusCode
-		var intermediary = struct {
-			Events    []map[string]interface{} `json:"events"`
-			Documents []map[string]interface{} `json:"docs"`
-		}{}
-		err = json.NewDecoder(resp.Body).Decode(&intermediary)
-		if err != nil {
-			return &types.DocumentsOpResult{
-				Err: err,
-			}
-		}
-		bd := new(bytes.Buffer)
-		err = json.NewEncoder(bd).Encode(intermediary.Documents)
-		if err != nil {
-			return &types.DocumentsOpResult{
-				Err: err,
-			}
-		}
-		docOpRes.Documents = bd
-		be := new(bytes.Buffer)
-		err = json.NewEncoder(be).Encode(intermediary.Events)
-		if err != nil {
-			return &types.DocumentsOpResult{
-				Err: err,
-			}
-		}
-		docOpRes.Events = be
-		return docOpRes
+		return prepareDocumentsOpResult(resp)
 	}
 	msg, err := prepareExceptionResponse(resp)
 	return &types.DocumentsOpResult{
@@ -129,59 +91,78 @@ func (c *clientImpl) UpdateQuery(params *types.ModifyingQueryParams) *types.Docu
 	}
 }
 
-// RemoveQuery deals with executing the provided removal AQL query through the
-// foxx service endpoint and returns a result with all the removed documents and each removal
-// operation event.
-func (c *clientImpl) RemoveQuery(params *types.ModifyingQueryParams) *types.DocumentsOpResult {
-	b := new(bytes.Buffer)
-	err := json.NewEncoder(b).Encode(params)
+// Deals with parsing the documents and events from a successful modifying query response.
+func prepareDocumentsOpResult(resp *http.Response) *types.DocumentsOpResult {
+	docOpRes := &types.DocumentsOpResult{}
+	docOpRes.StatusCode = resp.StatusCode
+	var intermediary = struct {
+		Events    []map[string]interface{} `json:"events"`
+		Documents []map[string]interface{} `json:"docs"`
+		Old       []map[string]interface{} `json:"old"`
+		New       []map[string]interface{} `json:"new"`
+	}{}
+	err := json.NewDecoder(resp.Body).Decode(&intermediary)
 	if err != nil {
 		return &types.DocumentsOpResult{
 			Err: err,
 		}
 	}
-	req := c.prepareRequest("POST", "/remove", nil, b)
-	resp, err := c.httpClient.Do(req)
+	bd := new(bytes.Buffer)
+	err = json.NewEncoder(bd).Encode(intermediary.Documents)
 	if err != nil {
 		return &types.DocumentsOpResult{
 			Err: err,
 		}
 	}
-	if resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusOK {
-		docOpRes := &types.DocumentsOpResult{}
-		docOpRes.StatusCode = resp.StatusCode
-		var intermediary = struct {
-			Events    []map[string]interface{} `json:"events"`
-			Documents []map[string]interface{} `json:"docs"`
-		}{}
-		err = json.NewDecoder(resp.Body).Decode(&intermediary)
-		if err != nil {
-			return &types.DocumentsOpResult{
-				Err: err,
-			}
+	docOpRes.Documents = bd
+	be := new(bytes.Buffer)
+	err = json.NewEncoder(be).Encode(intermediary.Events)
+	if err != nil {
+		return &types.DocumentsOpResult{
+			Err: err,
 		}
-		bd := new(bytes.Buffer)
-		err = json.NewEncoder(bd).Encode(intermediary.Documents)
+	}
+	docOpRes.DecodedEvents = decodeEvents(be.Bytes())
+	docOpRes.Events = be
+	if intermediary.Old != nil {
+		bo := new(bytes.Buffer)
+		err = json.NewEncoder(bo).Encode(intermediary.Old)
 		if err != nil {
 			return &types.DocumentsOpResult{
 				Err: err,
 			}
 		}
-		docOpRes.Documents = bd
-		be := new(bytes.Buffer)
-		err = json.NewEncoder(be).Encode(intermediary.Events)
+		docOpRes.Old = bo
+	}
+	if intermediary.New != nil {
+		bn := new(bytes.Buffer)
+		err = json.NewEncoder(bn).Encode(intermediary.New)
 		if err != nil {
 			return &types.DocumentsOpResult{
 				Err: err,
 			}
 		}
-		docOpRes.Events = be
-		return docOpRes
+		docOpRes.New = bn
 	}
-	msg, err := prepareExceptionResponse(resp)
-	return &types.DocumentsOpResult{
-		Err:        err,
-		Message:    msg,
-		StatusCode: resp.StatusCode,
+	return docOpRes
+}
+
+// Deals with validating the modifying query parameters and preparing a copy to be sent
+// where WriteCollections includes the single WriteCollection, WriteCollection is also set
+// to the first of the write collections for services that only support a single one.
+// The schema version defaults to the current version of the request schema.
+func prepareModifyingQueryParams(params *types.ModifyingQueryParams) (*types.ModifyingQueryParams, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+	prepared := *params
+	prepared.WriteCollections = params.AllWriteCollections()
+	if prepared.WriteCollection == "" && len(prepared.WriteCollections) > 0 {
+		prepared.WriteCollection = prepared.WriteCollections[0]
+	}
+	if prepared.SchemaVersion == 0 {
+		prepared.SchemaVersion = types.ModifyingQuerySchemaVersion
 	}
+	return &prepared, nil
 }
@@ -3,6 +3,8 @@ package client_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -14,10 +16,13 @@ import (
 
 type QueriesSuite struct {
 	client Client
+	tc     *queriesTestClient
 }
 
 type queriesTestClient struct {
 	dummySessionClient
+	// The raw body of the last request made, used to pin the wire format.
+	lastBody []byte
 }
 
 func newQueriesTestHttpClient() WebClient {
@@ -29,6 +34,8 @@ func newQueriesTestHttpClient() WebClient {
 func (c *queriesTestClient) Do(req *http.Request) (resp *http.Response, err error) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/_db//microfoxx")
+		c.lastBody, _ = ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(c.lastBody))
 		switch path {
 		case "/insert":
 			c.insert(w, r)
@@ -36,6 +43,10 @@ func (c *queriesTestClient) Do(req *http.Request) (resp *http.Response, err erro
 			c.update(w, r)
 		case "/remove":
 			c.remove(w, r)
+		case "/replace":
+			c.modify(w, r, types.EventReplace)
+		case "/upsert":
+			c.modify(w, r, types.EventUpdate)
 		}
 	}))
 	defer server.Close()
@@ -51,14 +62,37 @@ func (c *queriesTestClient) insert(w http.ResponseWriter, req *http.Request) {
 	// Try to retrieve the modifying query parameters from the request.
 	var params types.ModifyingQueryParams
 	json.NewDecoder(req.Body).Decode(&params)
+	if params.Query == multiCollectionQuery {
+		declared := make(map[string]bool)
+		for _, coll := range append(params.WriteCollections, params.ExclusiveCollections...) {
+			declared[coll] = true
+		}
+		if params.WriteCollection == "orders" && declared["orders"] && declared["inventory"] {
+			w.WriteHeader(http.StatusCreated)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte("{\"docs\":[],\"events\":[]}"))
+		} else {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte("{\"exception\":\"Error 2016: The collections to be written to were not declared\"}"))
+		}
+		return
+	}
 	if params.Query == "FOR i in 1..100 INSERT { value: i, type: @type } IN test" {
 		if _, exists := params.BindVars["type"]; exists {
 			if params.WriteCollection == "test" {
 				w.WriteHeader(http.StatusCreated)
 				w.Header().Set("Content-Type", "application/json; charset=utf-8")
 				respData := make(map[string]interface{})
-				respData["docs"] = make([]map[string]interface{}, 0)
-				respData["events"] = make([]map[string]interface{}, 0)
+				respData["docs"] = []map[string]interface{}{{"_key": "1", "value": 1}}
+				respData["events"] = []*types.Event{{Op: types.EventInsert, Collection: "test", Key: "1", NewRev: "_a"}}
+				if params.ReturnOld {
+					// Inserted documents have no previous version.
+					respData["old"] = []interface{}{nil}
+				}
+				if params.ReturnNew {
+					respData["new"] = []map[string]interface{}{{"_key": "1", "_rev": "_a", "value": 1, "type": "a"}}
+				}
 				b := new(bytes.Buffer)
 				json.NewEncoder(b).Encode(respData)
 				w.Write(b.Bytes())
@@ -82,6 +116,12 @@ func (c *queriesTestClient) insert(w http.ResponseWriter, req *http.Request) {
 func (c *queriesTestClient) update(w http.ResponseWriter, req *http.Request) {
 	var params types.ModifyingQueryParams
 	json.NewDecoder(req.Body).Decode(&params)
+	if params.Query == inOperatorQuery && params.WriteCollection == "users" {
+		w.WriteHeader(http.StatusCreated)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte("{\"docs\":[],\"events\":[]}"))
+		return
+	}
 	if params.Query == "FOR t IN test FILTER t.type=@type UPDATE t WITH { status: 'inactive' } IN test" {
 		if _, exists := params.BindVars["type"]; exists {
 			if params.WriteCollection == "test" {
@@ -141,12 +181,40 @@ func (c *queriesTestClient) remove(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// Uses the IN operator inside the update expression, blocked is a variable and not a collection.
+const inOperatorQuery = "LET blocked = @blocked FOR u IN users " +
+	"UPDATE u WITH { flagged: u.country IN blocked } IN users"
+
+const multiCollectionQuery = "FOR o IN @orders INSERT o INTO orders " +
+	"UPDATE { _key: o.item } WITH { stock: OLD.stock - 1 } IN @@stock"
+
+// Deals with responding to replace and upsert queries with the document
+// provided in the bind variables and an event for the provided operation.
+func (c *queriesTestClient) modify(w http.ResponseWriter, req *http.Request, op types.EventOp) {
+	var params types.ModifyingQueryParams
+	json.NewDecoder(req.Body).Decode(&params)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	doc, ok := params.BindVars["doc"].(map[string]interface{})
+	if !ok || params.WriteCollection != "test" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("{\"exception\":\"Error 1552: bind parameter 'doc' was not declared in the query\"}"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	b, _ := json.Marshal(map[string]interface{}{
+		"docs":   []interface{}{doc},
+		"events": []*types.Event{{Op: op, Collection: "test", Key: doc["_key"].(string)}},
+	})
+	w.Write(b)
+}
+
 var _ = Suite(&QueriesSuite{})
 
 func (s *QueriesSuite) SetUpSuite(c *C) {
 	// Simply provide an empty set of connection parameters as our test HTTP client
 	// doesn't care about the url, just the request body for testing the cursor functionality.
-	cli, err := NewClient(&types.ConnectionParams{}, newQueriesTestHttpClient())
+	s.tc = newQueriesTestHttpClient().(*queriesTestClient)
+	cli, err := NewClient(&types.ConnectionParams{}, s.tc)
 	if err != nil {
 		c.Error("Failed to setup our client for testing.")
 	}
@@ -176,11 +244,12 @@ func (s *QueriesSuite) TestInsertQuery(c *C) {
 	params.BindVars = map[string]interface{}{
 		"type": "test",
 	}
+	// This is now caught before the request is made.
 	params.WriteCollection = "user"
 	res = s.client.InsertQuery(params)
-	c.Assert(res.Err, Equals, ErrGeneral)
-	c.Assert(res.Message, Equals, "Error 2016: The collection to be written to was not defined as the write collection")
-	c.Assert(res.StatusCode, Equals, http.StatusPreconditionFailed)
+	c.Assert(errors.Is(res.Err, types.ErrUndeclaredCollection), Equals, true)
+	c.Assert(res.Message, Equals, "")
+	c.Assert(res.StatusCode, Equals, 0)
 	c.Assert(res.Documents, Equals, nil)
 	c.Assert(res.Events, Equals, nil)
 	// Now try to write with a valid request.
@@ -188,13 +257,16 @@ func (s *QueriesSuite) TestInsertQuery(c *C) {
 	res = s.client.InsertQuery(params)
 	c.Assert(res.Err, Equals, nil)
 	c.Assert(res.Message, Equals, "")
+	c.Assert(len(res.DecodedEvents), Equals, 1)
+	c.Assert(res.DecodedEvents[0].Op, Equals, types.EventInsert)
+	c.Assert(res.DecodedEvents[0].Key, Equals, "1")
 	// Now ensure we can successfully decode the list of documents and events.
-	var docs []map[string]string
+	var docs []map[string]interface{}
 	err := json.NewDecoder(res.Documents).Decode(&docs)
 	if err != nil {
 		c.Error("Failed to decode documents")
 	}
-	var evts []map[string]string
+	var evts []map[string]interface{}
 	err = json.NewDecoder(res.Events).Decode(&evts)
 	if err != nil {
 		c.Error("Failed to decode events")
@@ -224,11 +296,12 @@ func (s *QueriesSuite) TestUpdateQuery(c *C) {
 	params.BindVars = map[string]interface{}{
 		"type": "test",
 	}
+	// This is now caught before the request is made.
 	params.WriteCollection = "user"
 	res = s.client.UpdateQuery(params)
-	c.Assert(res.Err, Equals, ErrGeneral)
-	c.Assert(res.Message, Equals, "Error 2016: The collection to be written to was not defined as the write collection")
-	c.Assert(res.StatusCode, Equals, http.StatusPreconditionFailed)
+	c.Assert(errors.Is(res.Err, types.ErrUndeclaredCollection), Equals, true)
+	c.Assert(res.Message, Equals, "")
+	c.Assert(res.StatusCode, Equals, 0)
 	c.Assert(res.Documents, Equals, nil)
 	c.Assert(res.Events, Equals, nil)
 	// Now try to write with a valid request.
@@ -272,11 +345,12 @@ func (s *QueriesSuite) TestRemoveQuery(c *C) {
 	params.BindVars = map[string]interface{}{
 		"type": "test",
 	}
+	// This is now caught before the request is made.
 	params.WriteCollection = "user"
 	res = s.client.RemoveQuery(params)
-	c.Assert(res.Err, Equals, ErrGeneral)
-	c.Assert(res.Message, Equals, "Error 2016: The collection to be written to was not defined as the write collection")
-	c.Assert(res.StatusCode, Equals, http.StatusPreconditionFailed)
+	c.Assert(errors.Is(res.Err, types.ErrUndeclaredCollection), Equals, true)
+	c.Assert(res.Message, Equals, "")
+	c.Assert(res.StatusCode, Equals, 0)
 	c.Assert(res.Documents, Equals, nil)
 	c.Assert(res.Events, Equals, nil)
 	// Now try to write with a valid request.
@@ -296,3 +370,160 @@ func (s *QueriesSuite) TestRemoveQuery(c *C) {
 		c.Error("Failed to decode events")
 	}
 }
+
+func (s *QueriesSuite) TestMultiCollectionQuery(c *C) {
+	params := &types.ModifyingQueryParams{
+		WriteCollections: []string{"orders", "inventory"},
+		Query:            multiCollectionQuery,
+		BindVars: map[string]interface{}{
+			"orders": []map[string]interface{}{{"item": "i1"}},
+			"@stock": "inventory",
+		},
+	}
+	res := s.client.InsertQuery(params)
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusCreated)
+	// Exclusive collections count as declared and the single write
+	// collection is still supported.
+	params.WriteCollections = nil
+	params.WriteCollection = "orders"
+	params.ExclusiveCollections = []string{"inventory"}
+	res = s.client.InsertQuery(params)
+	c.Assert(res.Err, IsNil)
+	// A target resolved from a bind parameter must also be declared.
+	params.ExclusiveCollections = nil
+	res = s.client.InsertQuery(params)
+	c.Assert(errors.Is(res.Err, types.ErrUndeclaredCollection), Equals, true)
+	c.Assert(res.Err.Error(), Equals, "The query writes to a collection that hasn't been declared: inventory")
+	c.Assert(res.StatusCode, Equals, 0)
+	c.Assert(params.WriteTargets(), DeepEquals, []string{"orders", "inventory"})
+}
+
+func (s *QueriesSuite) TestInOperatorInModification(c *C) {
+	// The IN operator in the update expression doesn't make blocked a write collection
+	// so the query is sent with only users declared.
+	res := s.client.UpdateQuery(&types.ModifyingQueryParams{
+		WriteCollections: []string{"users"},
+		Query:            inOperatorQuery,
+		BindVars:         map[string]interface{}{"blocked": []string{"xx"}},
+	})
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusCreated)
+}
+
+func (s *QueriesSuite) TestWriteTargets(c *C) {
+	params := &types.ModifyingQueryParams{
+		Query:    inOperatorQuery,
+		BindVars: map[string]interface{}{"blocked": []string{"xx"}},
+	}
+	c.Assert(params.WriteTargets(), DeepEquals, []string{"users"})
+	// Collections that aren't declared are still caught with the IN operator in the query.
+	params.WriteCollections = []string{"blocked"}
+	res := s.client.UpdateQuery(params)
+	c.Assert(errors.Is(res.Err, types.ErrUndeclaredCollection), Equals, true)
+	c.Assert(res.Err.Error(), Equals, "The query writes to a collection that hasn't been declared: users")
+	c.Assert(res.StatusCode, Equals, 0)
+	queries := map[string][]string{
+		// The operations of an upsert write to a single collection.
+		"UPSERT { _key: @key } INSERT { _key: @key, n: 1 } UPDATE { n: OLD.n + 1 } IN counters": {"counters"},
+		// Subqueries, quoted names and bind parameters.
+		"FOR o IN orders LET r = (INSERT { o: o._key } INTO `order-log` RETURN NEW) " +
+			"REMOVE o IN @@coll": {"order-log", "archive"},
+		// Attributes named after keywords, strings and comments aren't operations.
+		"FOR d IN docs FILTER d.update IN ['a', 'insert into x'] /* REMOVE d IN y */ " +
+			"REPLACE d WITH { remove: d.insert IN tags, into: 1 } IN docs": {"docs"},
+		"FOR i IN 1..10 INSERT { v: i } INTO numbers OPTIONS { ignoreErrors: true }": {"numbers"},
+	}
+	for query, targets := range queries {
+		params = &types.ModifyingQueryParams{Query: query, BindVars: map[string]interface{}{"@coll": "archive"}}
+		c.Assert(params.WriteTargets(), DeepEquals, targets, Commentf(query))
+	}
+}
+
+func (s *QueriesSuite) TestModifyingQueryWireFormat(c *C) {
+	params := &types.ModifyingQueryParams{
+		WriteCollection:      "test",
+		ExclusiveCollections: []string{"counters"},
+		ReadCollections:      []string{"types"},
+		Query:                "FOR i in 1..100 INSERT { value: i, type: @type } IN test",
+		BindVars:             map[string]interface{}{"type": "a"},
+		WaitForSync:          true,
+		ReturnOld:            true,
+		ReturnNew:            true,
+		IgnoreErrors:         true,
+	}
+	res := s.client.InsertQuery(params)
+	c.Assert(res.Err, IsNil)
+	c.Assert(string(s.tc.lastBody), Equals, "{\"schemaVersion\":1,\"writeCollection\":\"test\","+
+		"\"writeCollections\":[\"test\"],\"exclusiveCollections\":[\"counters\"],"+
+		"\"readCollections\":[\"types\"],\"query\":\"FOR i in 1..100 INSERT { value: i, type: @type } IN test\","+
+		"\"bindVars\":{\"type\":\"a\"},\"waitForSync\":true,\"returnOld\":true,"+
+		"\"returnNew\":true,\"ignoreErrors\":true}\n")
+	// The documents before and after the changes are provided when requested.
+	c.Assert(res.Old, NotNil)
+	var old []map[string]interface{}
+	c.Assert(json.NewDecoder(res.Old).Decode(&old), IsNil)
+	c.Assert(len(old), Equals, 1)
+	c.Assert(old[0], IsNil)
+	c.Assert(res.New, NotNil)
+	var docs []map[string]interface{}
+	c.Assert(json.NewDecoder(res.New).Decode(&docs), IsNil)
+	c.Assert(len(docs), Equals, 1)
+	c.Assert(docs[0]["_rev"], Equals, "_a")
+	c.Assert(docs[0]["type"], Equals, "a")
+	// Without ReturnOld and ReturnNew neither is provided.
+	params.ReturnOld = false
+	params.ReturnNew = false
+	res = s.client.InsertQuery(params)
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.Old, IsNil)
+	c.Assert(res.New, IsNil)
+	// Options that aren't set are left out of the request.
+	params = &types.ModifyingQueryParams{
+		Query: "FOR d IN test FILTER d.type == 'a' REMOVE d IN test",
+	}
+	s.client.RemoveQuery(params)
+	c.Assert(string(s.tc.lastBody), Equals, "{\"schemaVersion\":1,"+
+		"\"query\":\"FOR d IN test FILTER d.type == 'a' REMOVE d IN test\"}\n")
+	// The caller's params are left untouched.
+	c.Assert(params.SchemaVersion, Equals, 0)
+}
+
+func (s *QueriesSuite) TestReplaceAndUpsertQuery(c *C) {
+	params := &types.ModifyingQueryParams{
+		WriteCollection: "test",
+		Query:           "REPLACE @doc IN test",
+		BindVars:        map[string]interface{}{"doc": map[string]interface{}{"_key": "1", "value": 2}},
+	}
+	res := s.client.ReplaceQuery(params)
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	var docs []map[string]interface{}
+	c.Assert(json.NewDecoder(res.Documents).Decode(&docs), IsNil)
+	c.Assert(docs[0]["value"], Equals, float64(2))
+	c.Assert(res.DecodedEvents[0].Op, Equals, types.EventReplace)
+	params.Query = "UPSERT { _key: @doc._key } INSERT @doc UPDATE @doc IN test"
+	res = s.client.UpsertQuery(params)
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.DecodedEvents[0].Op, Equals, types.EventUpdate)
+	c.Assert(res.DecodedEvents[0].Key, Equals, "1")
+	// Errors from the service are reported as with the other kinds of query.
+	params.BindVars = nil
+	res = s.client.ModifyingQuery(types.QueryUpsert, params)
+	c.Assert(res.Err, Equals, ErrBadRequest)
+	c.Assert(res.Message, Equals, "Error 1552: bind parameter 'doc' was not declared in the query")
+}
+
+func (s *QueriesSuite) TestModifyingQueryKind(c *C) {
+	params := &types.ModifyingQueryParams{
+		WriteCollection: "test",
+		Query:           "FOR i in 1..100 INSERT { value: i, type: @type } IN test",
+		BindVars:        map[string]interface{}{"type": "a"},
+	}
+	res := s.client.ModifyingQuery(types.QueryInsert, params)
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusCreated)
+	res = s.client.ModifyingQuery(types.QueryKind("truncate"), params)
+	c.Assert(res.Err, Equals, ErrInvalidQueryKind)
+	c.Assert(res.StatusCode, Equals, 0)
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+
+	"github.com/freshwebio/go-microfoxx/types"
+)
+
+// SchemaClient provides the basis for a client that handles the JSON Schema
+// validation rules of the collections in the underlying data store service.
+type SchemaClient interface {
+	SetCollSchema(string, *types.CollectionSchema) *types.CollectionSchemaResult
+	GetCollSchema(string) *types.CollectionSchemaResult
+	RemoveCollSchema(string) *types.CollectionSchemaResult
+}
+
+// Matches the error number the data store uses for documents that fail schema validation.
+var schemaViolationRegExp = regexp.MustCompile("\\bError 1620\\b")
+
+// SchemaViolationError is the error returned when a document is rejected because it doesn't
+// match the schema of its collection, Message is the message provided by the data store service
+// which is the message of the schema when one has been set. It wraps ErrBadRequest.
+type SchemaViolationError struct {
+	Message string
+}
+
+func (e *SchemaViolationError) Error() string {
+	return "The document doesn't match the schema of the collection: " + e.Message
+}
+
+// Unwrap provides ErrBadRequest so schema violations are still treated as bad requests.
+func (e *SchemaViolationError) Unwrap() error {
+	return ErrBadRequest
+}
+
+// Deals with providing a *SchemaViolationError for bad requests caused by
+// a schema violation, any other error is left as it is.
+func documentError(msg string, err error) error {
+	if err == ErrBadRequest && schemaViolationRegExp.MatchString(msg) {
+		return &SchemaViolationError{Message: msg}
+	}
+	return err
+}
+
+// SetCollSchema deals with attaching the provided schema to the collection with the provided name,
+// replacing any existing schema. The schema is validated before the request is made.
+func (c *clientImpl) SetCollSchema(name string, schema *types.CollectionSchema) *types.CollectionSchemaResult {
+	if schema == nil {
+		return &types.CollectionSchemaResult{Err: types.ErrInvalidSchema}
+	}
+	if err := schema.Validate(); err != nil {
+		return &types.CollectionSchemaResult{Err: err}
+	}
+	return c.collSchemaRequest(name, schema)
+}
+
+// GetCollSchema deals with retrieving the schema of the collection with the provided name.
+func (c *clientImpl) GetCollSchema(name string) *types.CollectionSchemaResult {
+	return schemaResult(c.GetCollProperties(name))
+}
+
+// RemoveCollSchema deals with removing the schema from the collection with the provided name
+// so its documents are no longer validated.
+func (c *clientImpl) RemoveCollSchema(name string) *types.CollectionSchemaResult {
+	return c.collSchemaRequest(name, nil)
+}
+
+// Deals with changing the schema of a collection, a nil schema is sent as
+// an explicit null which is how the data store removes the schema.
+func (c *clientImpl) collSchemaRequest(name string, schema *types.CollectionSchema) *types.CollectionSchemaResult {
+	b := new(bytes.Buffer)
+	err := json.NewEncoder(b).Encode(struct {
+		Schema *types.CollectionSchema `json:"schema"`
+	}{Schema: schema})
+	if err != nil {
+		return &types.CollectionSchemaResult{Err: err}
+	}
+	return schemaResult(c.collPropertiesRequest("PUT", name, b))
+}
+
+func schemaResult(propertiesRes *types.CollectionPropertiesResult) *types.CollectionSchemaResult {
+	schemaRes := &types.CollectionSchemaResult{
+		Err:        propertiesRes.Err,
+		StatusCode: propertiesRes.StatusCode,
+		Message:    propertiesRes.Message,
+	}
+	if propertiesRes.Properties != nil {
+		schemaRes.Schema = propertiesRes.Properties.Schema
+	}
+	return schemaRes
+}
@@ -0,0 +1,238 @@
+package client_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/freshwebio/go-microfoxx/client"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+type SchemaSuite struct {
+	client Client
+	tc     *collectionTestClient
+}
+
+type schemaTestAddress struct {
+	Street string `json:"street"`
+	Zip    string `json:"zip,omitempty"`
+}
+
+type schemaTestAudit struct {
+	CreatedAt time.Time `json:"createdAt"`
+	internal  string
+}
+
+type schemaTestModel struct {
+	schemaTestAudit
+	Key      string                 `json:"_key,omitempty"`
+	Name     string                 `json:"name"`
+	Age      int                    `json:"age"`
+	Score    float64                `json:"score,omitempty"`
+	Active   bool                   `json:"active"`
+	Tags     []string               `json:"tags"`
+	Avatar   []byte                 `json:"avatar,omitempty"`
+	Address  *schemaTestAddress     `json:"address"`
+	Counts   map[string]int         `json:"counts,omitempty"`
+	Extra    map[string]interface{} `json:"extra,omitempty"`
+	Manager  *schemaTestModel       `json:"manager,omitempty"`
+	Password string                 `json:"-"`
+	Nickname string
+	Nick     *string           `json:"nick"`
+	Aliases  []*string         `json:"aliases"`
+	Labels   map[string]string `json:"labels"`
+}
+
+var _ = Suite(&SchemaSuite{})
+
+func (s *SchemaSuite) SetUpTest(c *C) {
+	s.tc = newCollectionTestHttpClient()
+	cli, err := NewClient(&types.ConnectionParams{}, s.tc)
+	if err != nil {
+		c.Error("Failed to setup our client for testing.")
+	}
+	s.client = cli
+	c.Assert(s.client.CreateColl("users").Err, IsNil)
+}
+
+func (s *SchemaSuite) TestCollSchema(c *C) {
+	res := s.client.GetCollSchema("users")
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.Schema, IsNil)
+	rule, err := types.SchemaFromStruct(schemaTestAddress{})
+	c.Assert(err, IsNil)
+	res = s.client.SetCollSchema("users", &types.CollectionSchema{
+		Rule:    rule,
+		Level:   types.SchemaLevelModerate,
+		Message: "The user is invalid",
+	})
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(res.Schema.Level, Equals, types.SchemaLevelModerate)
+	res = s.client.GetCollSchema("users")
+	c.Assert(res.Err, IsNil)
+	c.Assert(string(res.Schema.Rule), Equals, string(rule))
+	c.Assert(res.Schema.Message, Equals, "The user is invalid")
+	// Removing the schema sends an explicit null.
+	res = s.client.RemoveCollSchema("users")
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.Schema, IsNil)
+	c.Assert(s.client.GetCollSchema("users").Schema, IsNil)
+	res = s.client.GetCollSchema("groups")
+	c.Assert(res.Err, Equals, ErrNotFound)
+	c.Assert(res.Message, Equals, "Error 1203: collection or view not found")
+}
+
+func (s *SchemaSuite) TestSetInvalidCollSchema(c *C) {
+	invalid := []*types.CollectionSchema{
+		nil,
+		{Level: types.SchemaLevelStrict},
+		{Rule: json.RawMessage("[]")},
+		{Rule: json.RawMessage("{\"type\":")},
+		{Rule: json.RawMessage("{\"type\":\"object\"}"), Level: "lenient"},
+	}
+	for _, schema := range invalid {
+		res := s.client.SetCollSchema("users", schema)
+		c.Assert(errors.Is(res.Err, types.ErrInvalidSchema), Equals, true)
+		c.Assert(res.StatusCode, Equals, 0)
+	}
+	c.Assert(s.client.GetCollSchema("users").Schema, IsNil)
+}
+
+func (s *SchemaSuite) TestSchemaFromStruct(c *C) {
+	rule, err := types.SchemaFromStruct(&schemaTestModel{})
+	c.Assert(err, IsNil)
+	var schema map[string]interface{}
+	c.Assert(json.Unmarshal(rule, &schema), IsNil)
+	c.Assert(schema["type"], Equals, "object")
+	c.Assert(schema["required"], DeepEquals, []interface{}{"createdAt", "name", "age", "active", "tags",
+		"Nickname", "aliases", "labels"})
+	properties := schema["properties"].(map[string]interface{})
+	c.Assert(len(properties), Equals, 16)
+	c.Assert(properties["createdAt"], DeepEquals, map[string]interface{}{"type": "string", "format": "date-time"})
+	c.Assert(properties["age"], DeepEquals, map[string]interface{}{"type": "integer"})
+	c.Assert(properties["score"], DeepEquals, map[string]interface{}{"type": "number"})
+	c.Assert(properties["active"], DeepEquals, map[string]interface{}{"type": "boolean"})
+	c.Assert(properties["avatar"], DeepEquals, map[string]interface{}{"type": "string"})
+	// Nil slices, maps and pointers without omitempty are encoded as null.
+	c.Assert(properties["tags"], DeepEquals, map[string]interface{}{
+		"type":  []interface{}{"array", "null"},
+		"items": map[string]interface{}{"type": "string"},
+	})
+	c.Assert(properties["address"], DeepEquals, map[string]interface{}{
+		"type":       []interface{}{"object", "null"},
+		"properties": map[string]interface{}{"street": map[string]interface{}{"type": "string"}, "zip": map[string]interface{}{"type": "string"}},
+		"required":   []interface{}{"street"},
+	})
+	c.Assert(properties["counts"], DeepEquals, map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": "integer"},
+	})
+	c.Assert(properties["extra"], DeepEquals, map[string]interface{}{"type": "object"})
+	// Recursive types end in a plain object.
+	c.Assert(properties["manager"], DeepEquals, map[string]interface{}{"type": "object"})
+	c.Assert(properties["nick"], DeepEquals, map[string]interface{}{"type": []interface{}{"string", "null"}})
+	c.Assert(properties["aliases"], DeepEquals, map[string]interface{}{
+		"type":  []interface{}{"array", "null"},
+		"items": map[string]interface{}{"type": []interface{}{"string", "null"}},
+	})
+	c.Assert(properties["labels"], DeepEquals, map[string]interface{}{
+		"type":                 []interface{}{"object", "null"},
+		"additionalProperties": map[string]interface{}{"type": "string"},
+	})
+	_, err = types.SchemaFromStruct("users")
+	c.Assert(errors.Is(err, types.ErrInvalidSchema), Equals, true)
+	_, err = types.SchemaFromStruct(nil)
+	c.Assert(errors.Is(err, types.ErrInvalidSchema), Equals, true)
+}
+
+func (s *SchemaSuite) TestSchemaFromStructMatchesEncoding(c *C) {
+	rule, err := types.SchemaFromStruct(schemaTestModel{})
+	c.Assert(err, IsNil)
+	var schema map[string]interface{}
+	c.Assert(json.Unmarshal(rule, &schema), IsNil)
+	nick := "ann"
+	for _, model := range []schemaTestModel{
+		{},
+		{Name: "Ann", Tags: []string{"a"}, Nick: &nick, Aliases: []*string{nil, &nick},
+			Address: &schemaTestAddress{Street: "High St"}, Counts: map[string]int{"a": 1}},
+	} {
+		b, err := json.Marshal(model)
+		c.Assert(err, IsNil)
+		var doc interface{}
+		c.Assert(json.Unmarshal(b, &doc), IsNil)
+		c.Assert(matchesSchema(schema, doc), Equals, "", Commentf("%s", b))
+	}
+	// Values of the wrong type still fail to match.
+	c.Assert(matchesSchema(schema, map[string]interface{}{"name": 1}), Not(Equals), "")
+}
+
+// Deals with checking a decoded JSON value against the subset of JSON Schema
+// derived from structs, the path to the first mismatch is returned.
+func matchesSchema(schema map[string]interface{}, value interface{}) string {
+	if schemaType, ok := schema["type"]; ok {
+		types := []interface{}{schemaType}
+		if list, ok := schemaType.([]interface{}); ok {
+			types = list
+		}
+		matched := false
+		for _, t := range types {
+			matched = matched || jsonType(value) == t || (t == "number" && jsonType(value) == "integer")
+		}
+		if !matched {
+			return "type"
+		}
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				return "required " + name.(string)
+			}
+		}
+		for name, fieldValue := range v {
+			fieldSchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				fieldSchema, ok = schema["additionalProperties"].(map[string]interface{})
+			}
+			if ok {
+				if mismatch := matchesSchema(fieldSchema, fieldValue); mismatch != "" {
+					return name + "." + mismatch
+				}
+			}
+		}
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		for _, item := range v {
+			if mismatch := matchesSchema(items, item); mismatch != "" {
+				return "items." + mismatch
+			}
+		}
+	}
+	return ""
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+	}
+	return "number"
+}
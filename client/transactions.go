@@ -0,0 +1,115 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/freshwebio/go-microfoxx/types"
+)
+
+const (
+	transactionEndpoint = "/transaction"
+)
+
+// TransactionClient provides the functionality to execute
+// several operations atomically on the data store service.
+type TransactionClient interface {
+	Transaction(*types.TransactionParams) *types.TransactionResult
+}
+
+// TransactionError is the error returned when a transaction has been rolled back,
+// none of the changes made by the transaction are kept. Statement is the index of
+// the statement that caused the rollback or -1 when it was caused by an action
+// or can't be attributed to a single statement.
+type TransactionError struct {
+	Statement int
+	Message   string
+}
+
+func (e *TransactionError) Error() string {
+	if e.Statement >= 0 {
+		return "The transaction was rolled back at statement " + strconv.Itoa(e.Statement) + ": " + e.Message
+	}
+	return "The transaction was rolled back: " + e.Message
+}
+
+// Transaction deals with executing the provided statements or action in a single transaction
+// so that either all of the changes are committed or none of them are.
+// When the transaction is rolled back the error of the result is a *TransactionError.
+func (c *clientImpl) Transaction(params *types.TransactionParams) *types.TransactionResult {
+	if (len(params.Statements) > 0) == (params.Action != "") {
+		return &types.TransactionResult{Err: ErrInvalidTransaction}
+	}
+	for _, statement := range params.Statements {
+		if statement == nil || statement.Query == "" {
+			return &types.TransactionResult{Err: ErrInvalidTransaction}
+		}
+	}
+	b := new(bytes.Buffer)
+	err := json.NewEncoder(b).Encode(params)
+	if err != nil {
+		return &types.TransactionResult{Err: err}
+	}
+	req := c.prepareRequest("POST", transactionEndpoint, nil, b)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &types.TransactionResult{Err: err}
+	}
+	var intermediary = struct {
+		Results []struct {
+			Documents []interface{}            `json:"docs"`
+			Events    []map[string]interface{} `json:"events"`
+		} `json:"results"`
+		Result     interface{} `json:"result"`
+		Exception  string      `json:"exception"`
+		RolledBack bool        `json:"rolledBack"`
+		Statement  *int        `json:"statement"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&intermediary)
+	if err != nil {
+		return &types.TransactionResult{Err: err, StatusCode: resp.StatusCode}
+	}
+	txRes := types.TransactionResult{StatusCode: resp.StatusCode}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		txRes.Message = intermediary.Exception
+		if intermediary.RolledBack {
+			txErr := &TransactionError{Statement: -1, Message: intermediary.Exception}
+			if intermediary.Statement != nil {
+				txErr.Statement = *intermediary.Statement
+			}
+			txRes.Err = txErr
+		} else {
+			txRes.Err = statusError(resp.StatusCode)
+		}
+		return &txRes
+	}
+	txRes.Results = make([]*types.StatementResult, 0, len(intermediary.Results))
+	for _, result := range intermediary.Results {
+		bd := new(bytes.Buffer)
+		err = json.NewEncoder(bd).Encode(result.Documents)
+		if err != nil {
+			return &types.TransactionResult{Err: err}
+		}
+		be := new(bytes.Buffer)
+		err = json.NewEncoder(be).Encode(result.Events)
+		if err != nil {
+			return &types.TransactionResult{Err: err}
+		}
+		txRes.Results = append(txRes.Results, &types.StatementResult{
+			Documents:     bd,
+			DecodedEvents: decodeEvents(be.Bytes()),
+			Events:        be,
+		})
+	}
+	if params.Action != "" {
+		br := new(bytes.Buffer)
+		err = json.NewEncoder(br).Encode(intermediary.Result)
+		if err != nil {
+			return &types.TransactionResult{Err: err}
+		}
+		txRes.ActionResult = br
+	}
+	return &txRes
+}
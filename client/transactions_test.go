@@ -0,0 +1,166 @@
+package client_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/freshwebio/go-microfoxx/client"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+type TransactionsSuite struct {
+	client Client
+}
+
+type transactionsTestClient struct {
+	dummySessionClient
+}
+
+func newTransactionsTestHttpClient() WebClient {
+	return &transactionsTestClient{}
+}
+
+// Deals with preparing a response for transaction requests.
+func (c *transactionsTestClient) Do(req *http.Request) (resp *http.Response, err error) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, "/_db//microfoxx") == "/transaction" {
+			c.transaction(w, r)
+		}
+	}))
+	defer server.Close()
+	newReq, _ := http.NewRequest(req.Method, server.URL+req.URL.Path, req.Body)
+	newReq.URL.RawQuery = req.URL.RawQuery
+	resp, err = http.DefaultClient.Do(newReq)
+	return resp, err
+}
+
+func (c *transactionsTestClient) transaction(w http.ResponseWriter, req *http.Request) {
+	var params types.TransactionParams
+	json.NewDecoder(req.Body).Decode(&params)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	declared := make(map[string]bool)
+	for _, coll := range append(params.WriteCollections, params.ExclusiveCollections...) {
+		declared[coll] = true
+	}
+	if !declared["orders"] {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("{\"exception\":\"Error 2016: The orders collection was not declared for writing\"}"))
+		return
+	}
+	if params.Action != "" {
+		w.WriteHeader(http.StatusOK)
+		b, _ := json.Marshal(map[string]interface{}{"result": params.Params["count"]})
+		w.Write(b)
+		return
+	}
+	results := make([]map[string]interface{}, 0)
+	for i, statement := range params.Statements {
+		// For the purpose of testing statements that remove documents
+		// fail and cause the whole transaction to be rolled back.
+		if strings.Contains(statement.Query, "REMOVE") {
+			w.WriteHeader(http.StatusConflict)
+			b, _ := json.Marshal(map[string]interface{}{
+				"exception":  "Error 1200: conflict, document was changed",
+				"rolledBack": true,
+				"statement":  i,
+			})
+			w.Write(b)
+			return
+		}
+		results = append(results, map[string]interface{}{
+			"docs":   []map[string]interface{}{{"_key": statement.BindVars["key"]}},
+			"events": []*types.Event{{Op: types.EventInsert, Key: statement.BindVars["key"].(string)}},
+		})
+	}
+	w.WriteHeader(http.StatusOK)
+	b, _ := json.Marshal(map[string]interface{}{"results": results})
+	w.Write(b)
+}
+
+var _ = Suite(&TransactionsSuite{})
+
+func (s *TransactionsSuite) SetUpSuite(c *C) {
+	cli, err := NewClient(&types.ConnectionParams{}, newTransactionsTestHttpClient())
+	if err != nil {
+		c.Error("Failed to setup our client for testing.")
+	}
+	s.client = cli
+}
+
+func (s *TransactionsSuite) TestTransactionStatements(c *C) {
+	params := &types.TransactionParams{
+		WriteCollections: []string{"orders", "inventory"},
+		Statements: []*types.TransactionStatement{
+			{
+				Query:    "INSERT { _key: @key } INTO orders",
+				BindVars: map[string]interface{}{"key": "o1"},
+			},
+			{
+				Query:    "UPDATE { _key: @key, stock: 4 } IN inventory",
+				BindVars: map[string]interface{}{"key": "i1"},
+			},
+		},
+	}
+	res := s.client.Transaction(params)
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(len(res.Results), Equals, 2)
+	var docs []map[string]interface{}
+	err := json.NewDecoder(res.Results[1].Documents).Decode(&docs)
+	c.Assert(err, IsNil)
+	c.Assert(docs[0]["_key"], Equals, "i1")
+	c.Assert(len(res.Results[0].DecodedEvents), Equals, 1)
+	c.Assert(res.Results[0].DecodedEvents[0].Key, Equals, "o1")
+	c.Assert(res.ActionResult, IsNil)
+	// A failing statement rolls back the transaction.
+	params.Statements = append(params.Statements, &types.TransactionStatement{
+		Query:    "REMOVE { _key: @key } IN orders",
+		BindVars: map[string]interface{}{"key": "o0"},
+	})
+	res = s.client.Transaction(params)
+	c.Assert(res.StatusCode, Equals, http.StatusConflict)
+	c.Assert(res.Message, Equals, "Error 1200: conflict, document was changed")
+	c.Assert(res.Results, IsNil)
+	txErr, ok := res.Err.(*TransactionError)
+	c.Assert(ok, Equals, true)
+	c.Assert(txErr.Statement, Equals, 2)
+	c.Assert(txErr.Error(), Equals, "The transaction was rolled back at statement 2: Error 1200: conflict, document was changed")
+	// Errors that aren't rollbacks are reported as usual.
+	params.WriteCollections = []string{"inventory"}
+	res = s.client.Transaction(params)
+	c.Assert(res.Err, Equals, ErrBadRequest)
+	c.Assert(res.Message, Equals, "Error 2016: The orders collection was not declared for writing")
+}
+
+func (s *TransactionsSuite) TestTransactionAction(c *C) {
+	res := s.client.Transaction(&types.TransactionParams{
+		ExclusiveCollections: []string{"orders"},
+		Action:               "function (params) { return params.count; }",
+		Params:               map[string]interface{}{"count": 3},
+	})
+	c.Assert(res.Err, IsNil)
+	var count int
+	err := json.NewDecoder(res.ActionResult).Decode(&count)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 3)
+}
+
+func (s *TransactionsSuite) TestInvalidTransaction(c *C) {
+	invalid := []*types.TransactionParams{
+		{WriteCollections: []string{"orders"}},
+		{
+			WriteCollections: []string{"orders"},
+			Action:           "function () {}",
+			Statements:       []*types.TransactionStatement{{Query: "RETURN 1"}},
+		},
+		{WriteCollections: []string{"orders"}, Statements: []*types.TransactionStatement{{}}},
+	}
+	for _, params := range invalid {
+		res := s.client.Transaction(params)
+		c.Assert(res.Err, Equals, ErrInvalidTransaction)
+		c.Assert(res.StatusCode, Equals, 0)
+	}
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/freshwebio/go-microfoxx/types"
+)
+
+const (
+	transactionBeginEndpoint = "/begin"
+)
+
+// StreamTransactionClient provides the functionality to run a series of
+// operations over several requests as a single transaction.
+type StreamTransactionClient interface {
+	BeginTransaction(*types.TransactionCollections, *types.TransactionOptions) (Tx, *types.TransactionStatusResult)
+	WithTransaction(*types.TransactionCollections, *types.TransactionOptions, func(Tx) error) error
+}
+
+// Tx provides a handle for a running stream transaction, every document, cursor
+// and query operation made through the handle is part of the transaction and
+// none of the changes are visible outside of it until it is committed.
+type Tx interface {
+	DocClient
+	CursorClient
+	QueryClient
+	NamedQueryClient
+	ID() string
+	Commit() *types.TransactionStatusResult
+	Abort() *types.TransactionStatusResult
+}
+
+type txImpl struct {
+	clientImpl
+}
+
+// BeginTransaction deals with beginning a new stream transaction on the declared collections,
+// the transaction must be finished with either Commit or Abort on the returned handle.
+// The handle is nil when the transaction couldn't be started.
+func (c *clientImpl) BeginTransaction(collections *types.TransactionCollections,
+	opts *types.TransactionOptions) (Tx, *types.TransactionStatusResult) {
+	if opts == nil {
+		opts = &types.TransactionOptions{}
+	}
+	b := new(bytes.Buffer)
+	err := json.NewEncoder(b).Encode(struct {
+		Collections *types.TransactionCollections `json:"collections"`
+		*types.TransactionOptions
+	}{Collections: collections, TransactionOptions: opts})
+	if err != nil {
+		return nil, &types.TransactionStatusResult{Err: err}
+	}
+	statusRes := c.transactionStatusRequest("POST", transactionEndpoint+transactionBeginEndpoint, b)
+	if statusRes.Err != nil {
+		return nil, statusRes
+	}
+	// The handle shares everything with the client, including its session, apart from
+	// the transaction identifier attached to each of its requests.
+	tx := &txImpl{clientImpl: *c}
+	tx.txID = statusRes.ID
+	return tx, statusRes
+}
+
+// WithTransaction deals with running the provided function within a new stream transaction,
+// the transaction is committed when the function succeeds and aborted when it returns
+// an error, panics or the commit fails. A panic is passed on once the transaction is aborted.
+func (c *clientImpl) WithTransaction(collections *types.TransactionCollections,
+	opts *types.TransactionOptions, fn func(tx Tx) error) (err error) {
+	tx, statusRes := c.BeginTransaction(collections, opts)
+	if statusRes.Err != nil {
+		return statusRes.Err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Abort()
+		}
+	}()
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+	statusRes = tx.Commit()
+	if statusRes.Err != nil {
+		// The transaction is aborted so it doesn't hold on to its locks until it times out.
+		return fmt.Errorf("Failed to commit transaction %s: %w", tx.ID(), statusRes.Err)
+	}
+	committed = true
+	return nil
+}
+
+// ID provides the identifier of the transaction.
+func (t *txImpl) ID() string {
+	return t.txID
+}
+
+// Commit deals with committing all of the changes made within the transaction.
+func (t *txImpl) Commit() *types.TransactionStatusResult {
+	return t.transactionStatusRequest("PUT", transactionEndpoint+"/"+t.txID, nil)
+}
+
+// Abort deals with discarding all of the changes made within the transaction.
+func (t *txImpl) Abort() *types.TransactionStatusResult {
+	return t.transactionStatusRequest("DELETE", transactionEndpoint+"/"+t.txID, nil)
+}
+
+// Deals with making a request that changes the status of a stream transaction
+// and parsing the identifier and new status of the transaction from the response.
+func (c *clientImpl) transactionStatusRequest(method string, path string, body io.Reader) *types.TransactionStatusResult {
+	req := c.prepareRequest(method, path, nil, body)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &types.TransactionStatusResult{Err: err}
+	}
+	var statusRes types.TransactionStatusResult
+	statusRes.StatusCode = resp.StatusCode
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		var intermediary = struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&intermediary)
+		if err != nil {
+			return &types.TransactionStatusResult{Err: err}
+		}
+		statusRes.ID = intermediary.ID
+		statusRes.Status = intermediary.Status
+	} else {
+		msg, err := prepareExceptionResponse(resp)
+		statusRes.Message = msg
+		statusRes.Err = err
+	}
+	return &statusRes
+}
@@ -0,0 +1,223 @@
+package client_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "github.com/freshwebio/go-microfoxx/client"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+type TxSuite struct {
+	client Client
+	tc     *txTestClient
+}
+
+type txTestClient struct {
+	dummySessionClient
+	mu           sync.Mutex
+	transactions map[string]string
+	// The transaction identifier of each document created, keyed by collection.
+	writes map[string][]string
+	// Whether committing a transaction fails with the service being unavailable.
+	failCommits bool
+	// The amount of sessions created and the session of each document created.
+	logins   int
+	sessions []string
+}
+
+// Deals with creating a new session for every login so requests
+// can be matched to the session they were made with.
+func (c *txTestClient) Post(url string, bodyType string, body io.Reader) (resp *http.Response, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logins++
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader("{\"sid\":\"s" + strconv.Itoa(c.logins) + "\",\"uid\":\"6789\"}")),
+	}, nil
+}
+
+// Deals with preparing a response for stream transaction requests and
+// the document requests made as part of them.
+func (c *txTestClient) Do(req *http.Request) (resp *http.Response, err error) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/_db//microfoxx")
+		statusRegExp := regexp.MustCompile("^/transaction/(\\w+)$")
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if path == "/transaction/begin" && r.Method == "POST" {
+			var params struct {
+				Collections types.TransactionCollections `json:"collections"`
+				IdleTimeout int                          `json:"idleTimeout"`
+			}
+			json.NewDecoder(r.Body).Decode(&params)
+			if len(params.Collections.Write) == 0 || params.IdleTimeout != 30 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("{\"exception\":\"Error 2016: Invalid transaction parameters\"}"))
+				return
+			}
+			id := strconv.Itoa(len(c.transactions) + 1)
+			c.transactions[id] = "running"
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{\"id\":\"" + id + "\",\"status\":\"running\"}"))
+		} else if statusRegExp.MatchString(path) {
+			id := statusRegExp.FindStringSubmatch(path)[1]
+			if c.transactions[id] != "running" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("{\"exception\":\"Error 1655: transaction not found\"}"))
+				return
+			}
+			if r.Method == "PUT" && c.failCommits {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("{\"exception\":\"Error 503: service unavailable\"}"))
+				return
+			}
+			if r.Method == "PUT" {
+				c.transactions[id] = "committed"
+			} else {
+				c.transactions[id] = "aborted"
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("{\"id\":\"" + id + "\",\"status\":\"" + c.transactions[id] + "\"}"))
+		} else if r.Method == "POST" {
+			txID := r.Header.Get("X-Transaction-Id")
+			if txID != "" && c.transactions[txID] != "running" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("{\"exception\":\"Error 1655: transaction not found\"}"))
+				return
+			}
+			coll := strings.TrimPrefix(path, "/")
+			c.writes[coll] = append(c.writes[coll], txID)
+			c.sessions = append(c.sessions, r.Header.Get("X-Session-Id"))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{\"doc\":{\"_key\":\"a\"},\"event\":{\"op\":\"insert\",\"key\":\"a\"}}"))
+		}
+	}))
+	defer server.Close()
+	newReq, _ := http.NewRequest(req.Method, server.URL+req.URL.Path, req.Body)
+	newReq.Header = req.Header
+	newReq.URL.RawQuery = req.URL.RawQuery
+	resp, err = http.DefaultClient.Do(newReq)
+	return resp, err
+}
+
+var _ = Suite(&TxSuite{})
+
+func (s *TxSuite) SetUpTest(c *C) {
+	s.tc = &txTestClient{
+		transactions: make(map[string]string),
+		writes:       make(map[string][]string),
+	}
+	cli, err := NewClient(&types.ConnectionParams{}, s.tc)
+	if err != nil {
+		c.Error("Failed to setup our client for testing.")
+	}
+	s.client = cli
+}
+
+var (
+	txCollections = &types.TransactionCollections{Write: []string{"orders", "inventory"}}
+	txOptions     = &types.TransactionOptions{IdleTimeout: 30}
+)
+
+func (s *TxSuite) TestBeginCommit(c *C) {
+	tx, res := s.client.BeginTransaction(txCollections, txOptions)
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusCreated)
+	c.Assert(res.Status, Equals, "running")
+	c.Assert(tx.ID(), Equals, "1")
+	// Operations through the handle carry the transaction identifier
+	// while operations through the client don't.
+	c.Assert(tx.CreateDoc("orders", map[string]string{"item": "a"}).Err, IsNil)
+	c.Assert(s.client.CreateDoc("inventory", map[string]string{"item": "a"}).Err, IsNil)
+	c.Assert(s.tc.writes["orders"], DeepEquals, []string{"1"})
+	c.Assert(s.tc.writes["inventory"], DeepEquals, []string{""})
+	res = tx.Commit()
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.Status, Equals, "committed")
+	// The transaction can't be used once it has been committed.
+	opRes := tx.CreateDoc("orders", map[string]string{"item": "b"})
+	c.Assert(opRes.Err, Equals, ErrNotFound)
+	res = tx.Abort()
+	c.Assert(res.Err, Equals, ErrNotFound)
+	c.Assert(res.Message, Equals, "Error 1655: transaction not found")
+}
+
+func (s *TxSuite) TestRefreshDuringTransaction(c *C) {
+	tx, res := s.client.BeginTransaction(txCollections, txOptions)
+	c.Assert(res.Err, IsNil)
+	c.Assert(tx.CreateDoc("orders", map[string]string{"item": "a"}).Err, IsNil)
+	// Refreshing the client's session also refreshes the session of the open transaction.
+	c.Assert(s.client.Refresh(), IsNil)
+	c.Assert(tx.CreateDoc("orders", map[string]string{"item": "b"}).Err, IsNil)
+	c.Assert(s.client.CreateDoc("inventory", map[string]string{"item": "b"}).Err, IsNil)
+	c.Assert(s.tc.sessions, DeepEquals, []string{"s1", "s2", "s2"})
+	c.Assert(s.tc.writes["orders"], DeepEquals, []string{"1", "1"})
+	c.Assert(tx.Commit().Err, IsNil)
+}
+
+func (s *TxSuite) TestBeginAbort(c *C) {
+	tx, res := s.client.BeginTransaction(txCollections, txOptions)
+	c.Assert(res.Err, IsNil)
+	res = tx.Abort()
+	c.Assert(res.Err, IsNil)
+	c.Assert(res.Status, Equals, "aborted")
+	c.Assert(s.tc.transactions[tx.ID()], Equals, "aborted")
+	// Failing to begin a transaction provides no handle.
+	tx, res = s.client.BeginTransaction(&types.TransactionCollections{Read: []string{"orders"}}, nil)
+	c.Assert(tx, IsNil)
+	c.Assert(res.Err, Equals, ErrBadRequest)
+}
+
+func (s *TxSuite) TestWithTransaction(c *C) {
+	err := s.client.WithTransaction(txCollections, txOptions, func(tx Tx) error {
+		return tx.CreateDoc("orders", map[string]string{"item": "a"}).Err
+	})
+	c.Assert(err, IsNil)
+	c.Assert(s.tc.transactions["1"], Equals, "committed")
+	// Errors abort the transaction and are passed on.
+	errFailed := errors.New("Failed")
+	err = s.client.WithTransaction(txCollections, txOptions, func(tx Tx) error {
+		return errFailed
+	})
+	c.Assert(err, Equals, errFailed)
+	c.Assert(s.tc.transactions["2"], Equals, "aborted")
+	// Panics abort the transaction and are passed on.
+	func() {
+		defer func() {
+			c.Assert(recover(), Equals, "boom")
+		}()
+		s.client.WithTransaction(txCollections, txOptions, func(tx Tx) error {
+			panic("boom")
+		})
+	}()
+	c.Assert(s.tc.transactions["3"], Equals, "aborted")
+	// Failing to begin is reported without running the function.
+	err = s.client.WithTransaction(&types.TransactionCollections{}, txOptions, func(tx Tx) error {
+		c.Error("The function shouldn't run")
+		return nil
+	})
+	c.Assert(err, Equals, ErrBadRequest)
+}
+
+func (s *TxSuite) TestWithTransactionCommitFails(c *C) {
+	s.tc.failCommits = true
+	err := s.client.WithTransaction(txCollections, txOptions, func(tx Tx) error {
+		return tx.CreateDoc("orders", map[string]string{"item": "a"}).Err
+	})
+	c.Assert(errors.Is(err, ErrGeneral), Equals, true)
+	c.Assert(err, ErrorMatches, "Failed to commit transaction 1: .*")
+	// The transaction isn't left running when the commit fails.
+	c.Assert(s.tc.transactions["1"], Equals, "aborted")
+}
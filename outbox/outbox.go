@@ -0,0 +1,368 @@
+// Package outbox provides a local transactional outbox for the events returned
+// from write operations on the microfoxx service so they can be forwarded to
+// a message bus without being lost when the process stops before publishing them.
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	recordAdd = "add"
+	recordAck = "ack"
+)
+
+var (
+	// ErrEmptyEvent is the error returned when an event to be added to the outbox
+	// is empty or null, such as the event of a failed operation.
+	ErrEmptyEvent = errors.New("The provided event is empty")
+	// ErrClosed is the error returned when using an outbox that has been closed.
+	ErrClosed = errors.New("The outbox has been closed")
+	// ErrCorruptLog is the error returned when opening an outbox whose log contains
+	// a complete record that can't be read, the log is left untouched.
+	ErrCorruptLog = errors.New("The outbox log contains a corrupt record")
+)
+
+// Message provides an event waiting to be published, ID is derived from the
+// content of the event so publishing the same event twice always produces the same ID
+// which allows consumers to discard duplicates.
+type Message struct {
+	ID   string          `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Publisher provides the functionality to publish messages to a message bus.
+// Messages are delivered at least once, so Publish can be called again for a message
+// that was already published when the process stops before it is acknowledged.
+type Publisher interface {
+	Publish(msg *Message) error
+}
+
+// A record in the append-only log of the outbox.
+type record struct {
+	Type string          `json:"type"`
+	ID   string          `json:"id"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Outbox persists events to an append-only file and publishes them in the order
+// they were added, an event is only removed from the outbox once it has been published.
+type Outbox struct {
+	path      string
+	file      *os.File
+	publisher Publisher
+	mu        sync.Mutex
+	flushMu   sync.Mutex
+	pending   []*Message
+	ids       map[string]bool
+	closed    bool
+	stop      chan struct{}
+	stopOnce  sync.Once
+	stopped   chan struct{}
+}
+
+// Open opens the outbox persisted at the provided path creating it if it doesn't exist,
+// any events that weren't published before the outbox was last closed are published
+// on the next call to Flush.
+func Open(path string, publisher Publisher) (*Outbox, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	o := &Outbox{
+		path:      path,
+		file:      file,
+		publisher: publisher,
+		ids:       make(map[string]bool),
+	}
+	err = o.replay()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return o, nil
+}
+
+// Deals with rebuilding the pending messages from the log, a partially written
+// final record left behind by a crash is discarded. Every record is written with its
+// trailing newline so only a last line without one can be left by a crash, any other
+// line that can't be read means the log is corrupt.
+func (o *Outbox) replay() error {
+	reader := bufio.NewReader(o.file)
+	var offset int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var rec record
+		if json.Unmarshal(data, &rec) != nil {
+			return fmt.Errorf("%w: line %d", ErrCorruptLog, line)
+		}
+		offset += int64(len(data))
+		switch rec.Type {
+		case recordAdd:
+			if !o.ids[rec.ID] {
+				o.ids[rec.ID] = true
+				o.pending = append(o.pending, &Message{ID: rec.ID, Data: rec.Data})
+			}
+		case recordAck:
+			o.removePending(rec.ID)
+		}
+	}
+	err := o.file.Truncate(offset)
+	if err != nil {
+		return err
+	}
+	_, err = o.file.Seek(offset, io.SeekStart)
+	return err
+}
+
+func (o *Outbox) removePending(id string) {
+	delete(o.ids, id)
+	for i, msg := range o.pending {
+		if msg.ID == id {
+			o.pending = append(o.pending[:i], o.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// Add persists the event read from the provided reader, such as the Event of a DocumentOpResult,
+// and provides the ID of the message it will be published as. Adding an event that
+// is already waiting to be published doesn't add it again.
+func (o *Outbox) Add(event io.Reader) (string, error) {
+	data, err := readEvent(event)
+	if err != nil {
+		return "", err
+	}
+	ids, err := o.add([]json.RawMessage{data})
+	if err != nil {
+		return "", err
+	}
+	return ids[0], nil
+}
+
+// AddAll persists each event of the JSON array read from the provided reader,
+// such as the Events of a DocumentsOpResult, and provides the IDs of the messages
+// in the same order.
+func (o *Outbox) AddAll(events io.Reader) ([]string, error) {
+	var list []json.RawMessage
+	err := json.NewDecoder(events).Decode(&list)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]json.RawMessage, 0, len(list))
+	for _, evt := range list {
+		compacted, err := compact(evt)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, compacted)
+	}
+	return o.add(data)
+}
+
+func (o *Outbox) add(events []json.RawMessage) ([]string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return nil, ErrClosed
+	}
+	ids := make([]string, 0, len(events))
+	b := new(bytes.Buffer)
+	added := make([]*Message, 0, len(events))
+	for _, data := range events {
+		id := messageID(data)
+		ids = append(ids, id)
+		if o.ids[id] {
+			continue
+		}
+		err := json.NewEncoder(b).Encode(&record{Type: recordAdd, ID: id, Data: data})
+		if err != nil {
+			return nil, err
+		}
+		o.ids[id] = true
+		added = append(added, &Message{ID: id, Data: data})
+	}
+	// All the events are written and synced together so that they are either
+	// all persisted before this returns or none of them are considered added.
+	err := o.write(b.Bytes())
+	if err != nil {
+		for _, msg := range added {
+			delete(o.ids, msg.ID)
+		}
+		return nil, err
+	}
+	o.pending = append(o.pending, added...)
+	return ids, nil
+}
+
+func (o *Outbox) write(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	_, err := o.file.Write(data)
+	if err != nil {
+		return err
+	}
+	return o.file.Sync()
+}
+
+// Pending provides the messages waiting to be published in the order they will be published.
+func (o *Outbox) Pending() []*Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]*Message{}, o.pending...)
+}
+
+// Flush publishes the pending messages in order, stopping at the first message that
+// fails to publish so the order is kept, the remaining messages are published on the
+// next call to Flush. The log is compacted once every message has been published.
+func (o *Outbox) Flush() error {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+	for _, msg := range o.Pending() {
+		err := o.publisher.Publish(msg)
+		if err != nil {
+			return err
+		}
+		err = o.ack(msg.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return o.compact()
+}
+
+func (o *Outbox) ack(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return ErrClosed
+	}
+	b, err := json.Marshal(&record{Type: recordAck, ID: id})
+	if err != nil {
+		return err
+	}
+	err = o.write(append(b, '\n'))
+	if err != nil {
+		return err
+	}
+	o.removePending(id)
+	return nil
+}
+
+// Deals with emptying the log when there is nothing left to publish
+// so it doesn't keep growing.
+func (o *Outbox) compact() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed || len(o.pending) > 0 {
+		return nil
+	}
+	err := o.file.Truncate(0)
+	if err != nil {
+		return err
+	}
+	_, err = o.file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	return o.file.Sync()
+}
+
+// Start publishes the pending messages in the background every interval
+// until the outbox is closed, failed messages are retried on the next interval.
+func (o *Outbox) Start(interval time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed || o.stop != nil {
+		return
+	}
+	o.stop = make(chan struct{})
+	o.stopped = make(chan struct{})
+	go func() {
+		defer close(o.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				o.Flush()
+			case <-o.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops publishing in the background and closes the outbox file,
+// messages that haven't been published stay in the file for the next time it is opened.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	stop, stopped := o.stop, o.stopped
+	o.mu.Unlock()
+	if stop != nil {
+		// Close can be called more than once so the background publishing is only stopped once.
+		o.stopOnce.Do(func() { close(stop) })
+		<-stopped
+	}
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return ErrClosed
+	}
+	o.closed = true
+	return o.file.Close()
+}
+
+// Deals with reading a single event ensuring it isn't empty.
+func readEvent(event io.Reader) (json.RawMessage, error) {
+	if event == nil {
+		return nil, ErrEmptyEvent
+	}
+	var data json.RawMessage
+	err := json.NewDecoder(event).Decode(&data)
+	if err == io.EOF {
+		return nil, ErrEmptyEvent
+	}
+	if err != nil {
+		return nil, err
+	}
+	return compact(data)
+}
+
+func compact(data json.RawMessage) (json.RawMessage, error) {
+	b := new(bytes.Buffer)
+	err := json.Compact(b, data)
+	if err != nil {
+		return nil, err
+	}
+	if b.String() == "null" || b.String() == "{}" {
+		return nil, ErrEmptyEvent
+	}
+	return b.Bytes(), nil
+}
+
+// Derives the message ID from the content of the event so the same event
+// always gets the same ID.
+func messageID(data json.RawMessage) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,206 @@
+package outbox_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/freshwebio/go-microfoxx/outbox"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type OutboxSuite struct {
+	path string
+}
+
+type testPublisher struct {
+	mu        sync.Mutex
+	published []*Message
+	failAfter int
+}
+
+var errPublish = errors.New("The message bus is unavailable")
+
+// Deals with recording published messages, failing once the configured amount
+// of messages has been published when failAfter is set.
+func (p *testPublisher) Publish(msg *Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failAfter > 0 && len(p.published) >= p.failAfter {
+		return errPublish
+	}
+	p.published = append(p.published, msg)
+	return nil
+}
+
+func (p *testPublisher) messages() []*Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Message{}, p.published...)
+}
+
+func eventReader(key string) *bytes.Buffer {
+	b := new(bytes.Buffer)
+	json.NewEncoder(b).Encode(&types.Event{Op: types.EventInsert, Collection: "test", Key: key, NewRev: "_" + key})
+	return b
+}
+
+var _ = Suite(&OutboxSuite{})
+
+func (s *OutboxSuite) SetUpTest(c *C) {
+	s.path = filepath.Join(c.MkDir(), "outbox.log")
+}
+
+func (s *OutboxSuite) TestAddAndFlush(c *C) {
+	publisher := &testPublisher{}
+	ob, err := Open(s.path, publisher)
+	c.Assert(err, IsNil)
+	defer ob.Close()
+	id1, err := ob.Add(eventReader("a"))
+	c.Assert(err, IsNil)
+	c.Assert(id1, Not(Equals), "")
+	// Adding the same event again gives the same ID without adding it twice.
+	id2, err := ob.Add(eventReader("a"))
+	c.Assert(err, IsNil)
+	c.Assert(id2, Equals, id1)
+	ids, err := ob.AddAll(strings.NewReader("[{\"op\":\"update\",\"key\":\"b\"},{\"op\":\"remove\",\"key\":\"c\"}]"))
+	c.Assert(err, IsNil)
+	c.Assert(len(ids), Equals, 2)
+	c.Assert(len(ob.Pending()), Equals, 3)
+	c.Assert(ob.Flush(), IsNil)
+	published := publisher.messages()
+	c.Assert(len(published), Equals, 3)
+	c.Assert(published[0].ID, Equals, id1)
+	c.Assert(published[1].ID, Equals, ids[0])
+	c.Assert(published[2].ID, Equals, ids[1])
+	var evt types.Event
+	c.Assert(json.Unmarshal(published[0].Data, &evt), IsNil)
+	c.Assert(evt.Key, Equals, "a")
+	c.Assert(len(ob.Pending()), Equals, 0)
+	// The log is emptied once everything has been published.
+	info, err := os.Stat(s.path)
+	c.Assert(err, IsNil)
+	c.Assert(info.Size(), Equals, int64(0))
+}
+
+func (s *OutboxSuite) TestEmptyEvents(c *C) {
+	ob, err := Open(s.path, &testPublisher{})
+	c.Assert(err, IsNil)
+	defer ob.Close()
+	_, err = ob.Add(nil)
+	c.Assert(err, Equals, ErrEmptyEvent)
+	_, err = ob.Add(strings.NewReader("null"))
+	c.Assert(err, Equals, ErrEmptyEvent)
+	_, err = ob.Add(strings.NewReader(""))
+	c.Assert(err, Equals, ErrEmptyEvent)
+	c.Assert(len(ob.Pending()), Equals, 0)
+}
+
+func (s *OutboxSuite) TestFailedPublishKeepsOrder(c *C) {
+	publisher := &testPublisher{failAfter: 1}
+	ob, err := Open(s.path, publisher)
+	c.Assert(err, IsNil)
+	defer ob.Close()
+	ob.Add(eventReader("a"))
+	ob.Add(eventReader("b"))
+	ob.Add(eventReader("c"))
+	c.Assert(ob.Flush(), Equals, errPublish)
+	pending := ob.Pending()
+	c.Assert(len(pending), Equals, 2)
+	// Publishing resumes from the first message that failed.
+	publisher.failAfter = 0
+	c.Assert(ob.Flush(), IsNil)
+	published := publisher.messages()
+	c.Assert(len(published), Equals, 3)
+	c.Assert(published[1].ID, Equals, pending[0].ID)
+	c.Assert(published[2].ID, Equals, pending[1].ID)
+}
+
+func (s *OutboxSuite) TestReopenAfterCrash(c *C) {
+	publisher := &testPublisher{failAfter: 1}
+	ob, err := Open(s.path, publisher)
+	c.Assert(err, IsNil)
+	ob.Add(eventReader("a"))
+	idB, _ := ob.Add(eventReader("b"))
+	ob.Flush()
+	ob.Close()
+	// Simulate a crash part way through writing a record.
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0644)
+	c.Assert(err, IsNil)
+	f.Write([]byte("{\"type\":\"add\",\"id\":\"trunc"))
+	f.Close()
+	// Only the event that wasn't published is left after reopening.
+	publisher = &testPublisher{}
+	ob, err = Open(s.path, publisher)
+	c.Assert(err, IsNil)
+	defer ob.Close()
+	pending := ob.Pending()
+	c.Assert(len(pending), Equals, 1)
+	c.Assert(pending[0].ID, Equals, idB)
+	idC, err := ob.Add(eventReader("c"))
+	c.Assert(err, IsNil)
+	c.Assert(ob.Flush(), IsNil)
+	published := publisher.messages()
+	c.Assert(len(published), Equals, 2)
+	c.Assert(published[0].ID, Equals, idB)
+	c.Assert(published[1].ID, Equals, idC)
+	data, err := ioutil.ReadFile(s.path)
+	c.Assert(err, IsNil)
+	c.Assert(len(data), Equals, 0)
+}
+
+func (s *OutboxSuite) TestReopenCorruptLog(c *C) {
+	ob, err := Open(s.path, &testPublisher{})
+	c.Assert(err, IsNil)
+	ob.Add(eventReader("a"))
+	ob.Close()
+	// A damaged record followed by more records isn't left by a crash.
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0644)
+	c.Assert(err, IsNil)
+	f.Write([]byte("{\"type\":\"add\",\"id\":\"trunc\n{\"type\":\"ack\",\"id\":\"x\"}\n"))
+	f.Close()
+	before, err := ioutil.ReadFile(s.path)
+	c.Assert(err, IsNil)
+	_, err = Open(s.path, &testPublisher{})
+	c.Assert(errors.Is(err, ErrCorruptLog), Equals, true)
+	after, err := ioutil.ReadFile(s.path)
+	c.Assert(err, IsNil)
+	c.Assert(string(after), Equals, string(before))
+}
+
+func (s *OutboxSuite) TestStart(c *C) {
+	publisher := &testPublisher{}
+	ob, err := Open(s.path, publisher)
+	c.Assert(err, IsNil)
+	ob.Start(time.Millisecond)
+	ob.Add(eventReader("a"))
+	deadline := time.Now().Add(time.Second)
+	for len(publisher.messages()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	c.Assert(len(publisher.messages()), Equals, 1)
+	c.Assert(ob.Close(), IsNil)
+	_, err = ob.Add(eventReader("b"))
+	c.Assert(err, Equals, ErrClosed)
+}
+
+func (s *OutboxSuite) TestCloseTwiceAfterStart(c *C) {
+	ob, err := Open(s.path, &testPublisher{})
+	c.Assert(err, IsNil)
+	ob.Start(time.Millisecond)
+	c.Assert(ob.Close(), IsNil)
+	c.Assert(ob.Close(), Equals, ErrClosed)
+	// Starting a closed outbox doesn't publish again.
+	ob.Start(time.Millisecond)
+	c.Assert(ob.Close(), Equals, ErrClosed)
+}
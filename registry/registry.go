@@ -0,0 +1,228 @@
+// Package registry provides a registry of named AQL queries loaded from .aql files,
+// usually embedded in the service with embed.FS, so queries are validated once at
+// startup and executed by name through the client.
+//
+// Each file provides a single query named after the file without its extension.
+// Leading comment lines can set the defaults for running the query:
+//
+//	// @batchSize 100
+//	// @kind update
+//	// @write users
+//	FOR u IN users FILTER u.lastSeen < @before UPDATE u WITH { active: false } IN users
+//
+// Queries without a kind are read queries run through a cursor, queries with a kind
+// of insert, update, replace, remove or upsert are run as modifying queries. Modifying
+// queries declare every collection they write to with one or more @write annotations,
+// a collection provided through a bind parameter is declared by its parameter such as @@orders.
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freshwebio/go-microfoxx/types"
+)
+
+const queryExt = ".aql"
+
+var (
+	// ErrInvalidQuery is the error returned when a query file is empty
+	// or one of its annotations is invalid.
+	ErrInvalidQuery = errors.New("The named query is invalid")
+	// ErrUnknownQuery is the error returned when no query has been registered with a name.
+	ErrUnknownQuery = errors.New("No query has been registered with the provided name")
+)
+
+// Matches the annotation comments at the start of a query file.
+var annotationRegExp = regexp.MustCompile("^//\\s*@(\\w+)\\s+(\\S+)\\s*$")
+
+// Query provides a named query along with the defaults for running it,
+// BindParams are the names of the bind parameters the query requires and
+// WriteCollections are the collections a modifying query writes to.
+type Query struct {
+	Name             string
+	Query            string
+	BatchSize        int
+	Kind             types.QueryKind
+	BindParams       []string
+	WriteCollections []string
+}
+
+// Metrics is notified each time a named query is run so the duration
+// and outcome of the query can be recorded under its name.
+type Metrics interface {
+	QueryExecuted(name string, duration time.Duration, statusCode int, err error)
+}
+
+// Parser provides the functionality to check the syntax of a query,
+// it is implemented by the microfoxx client.
+type Parser interface {
+	ParseQuery(string) *types.ParseResult
+}
+
+// Registry holds the named queries, it is safe for concurrent use once loaded.
+type Registry struct {
+	queries map[string]*Query
+	metrics Metrics
+}
+
+// Load deals with loading every .aql file in the directory of the provided
+// file system and its subdirectories, every query is checked while loading and
+// names must be unique across all the directories.
+func Load(fsys fs.FS, dir string) (*Registry, error) {
+	reg := &Registry{queries: make(map[string]*Query)}
+	err := fs.WalkDir(fsys, dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || path.Ext(filePath) != queryExt {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(path.Base(filePath), queryExt)
+		if _, exists := reg.queries[name]; exists {
+			return fmt.Errorf("%w: %s is defined more than once", ErrInvalidQuery, name)
+		}
+		query, err := parseQueryFile(name, string(data))
+		if err != nil {
+			return err
+		}
+		reg.queries[name] = query
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// Deals with reading the annotations at the start of the query file
+// and preparing the named query.
+func parseQueryFile(name string, data string) (*Query, error) {
+	query := &Query{Name: name, Query: strings.TrimSpace(data)}
+	for _, line := range strings.Split(query.Query, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+		annotation := annotationRegExp.FindStringSubmatch(line)
+		if annotation == nil {
+			continue
+		}
+		switch annotation[1] {
+		case "batchSize":
+			batchSize, err := strconv.Atoi(annotation[2])
+			if err != nil || batchSize <= 0 {
+				return nil, fmt.Errorf("%w: %s has an invalid batch size %q", ErrInvalidQuery, name, annotation[2])
+			}
+			query.BatchSize = batchSize
+		case "kind":
+			kind := types.QueryKind(annotation[2])
+			if !kind.Valid() {
+				return nil, fmt.Errorf("%w: %s has an unknown kind %q", ErrInvalidQuery, name, annotation[2])
+			}
+			query.Kind = kind
+		case "write":
+			query.WriteCollections = append(query.WriteCollections, annotation[2])
+		default:
+			return nil, fmt.Errorf("%w: %s has an unknown annotation @%s", ErrInvalidQuery, name, annotation[1])
+		}
+	}
+	query.BindParams = types.QueryBindParams(query.Query)
+	if stripComments(query.Query) == "" {
+		return nil, fmt.Errorf("%w: %s is empty", ErrInvalidQuery, name)
+	}
+	err := validateWriteCollections(query)
+	if err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// Deals with ensuring modifying queries declare the collections they write to,
+// collections declared by a bind parameter must be parameters of the query.
+func validateWriteCollections(query *Query) error {
+	if query.Kind == "" {
+		if len(query.WriteCollections) > 0 {
+			return fmt.Errorf("%w: %s is a read query and can't declare write collections", ErrInvalidQuery, query.Name)
+		}
+		return nil
+	}
+	if len(query.WriteCollections) == 0 {
+		return fmt.Errorf("%w: %s must declare the collections it writes to with @write", ErrInvalidQuery, query.Name)
+	}
+	for _, coll := range query.WriteCollections {
+		if !strings.HasPrefix(coll, "@@") {
+			continue
+		}
+		found := false
+		for _, param := range query.BindParams {
+			found = found || param == strings.TrimPrefix(coll, "@")
+		}
+		if !found {
+			return fmt.Errorf("%w: %s writes to %s which isn't a parameter of the query", ErrInvalidQuery, query.Name, coll)
+		}
+	}
+	return nil
+}
+
+func stripComments(query string) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(query, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "//") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetMetrics sets the metrics to be notified each time one of the queries is run,
+// it should be called before the registry is used.
+func (r *Registry) SetMetrics(metrics Metrics) {
+	r.metrics = metrics
+}
+
+// Metrics provides the metrics notified each time one of the queries is run, if any.
+func (r *Registry) Metrics() Metrics {
+	return r.metrics
+}
+
+// Query provides the query registered with the provided name.
+func (r *Registry) Query(name string) (*Query, error) {
+	query, ok := r.queries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownQuery, name)
+	}
+	return query, nil
+}
+
+// Names provides the names of every registered query in alphabetical order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.queries))
+	for name := range r.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate deals with checking the syntax of every registered query with the data store
+// service, it is meant to be called once at startup. The error provides the name of the
+// first query that failed to parse.
+func (r *Registry) Validate(parser Parser) error {
+	for _, name := range r.Names() {
+		res := parser.ParseQuery(r.queries[name].Query)
+		if res.Err != nil {
+			return fmt.Errorf("Failed to parse query %s: %w", name, res.Err)
+		}
+	}
+	return nil
+}
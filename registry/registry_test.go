@@ -0,0 +1,85 @@
+package registry_test
+
+import (
+	"embed"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/freshwebio/go-microfoxx/registry"
+	"github.com/freshwebio/go-microfoxx/types"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+//go:embed testdata/queries
+var testQueries embed.FS
+
+type RegistrySuite struct{}
+
+type testParser struct {
+	parsed []string
+}
+
+// Deals with failing to parse any query that doesn't start with a keyword.
+func (p *testParser) ParseQuery(query string) *types.ParseResult {
+	p.parsed = append(p.parsed, query)
+	if query == "RETRUN 1" {
+		return &types.ParseResult{Err: errors.New("syntax error")}
+	}
+	return &types.ParseResult{}
+}
+
+var _ = Suite(&RegistrySuite{})
+
+func (s *RegistrySuite) TestLoad(c *C) {
+	reg, err := Load(testQueries, "testdata/queries")
+	c.Assert(err, IsNil)
+	c.Assert(reg.Names(), DeepEquals, []string{"add_order", "deactivate_users", "find_active_users"})
+	query, err := reg.Query("find_active_users")
+	c.Assert(err, IsNil)
+	c.Assert(query.Name, Equals, "find_active_users")
+	c.Assert(query.BatchSize, Equals, 50)
+	c.Assert(query.Kind, Equals, types.QueryKind(""))
+	c.Assert(query.BindParams, DeepEquals, []string{"since"})
+	query, err = reg.Query("add_order")
+	c.Assert(err, IsNil)
+	c.Assert(query.Kind, Equals, types.QueryInsert)
+	c.Assert(query.BatchSize, Equals, 0)
+	c.Assert(query.BindParams, DeepEquals, []string{"order", "@orders"})
+	c.Assert(query.WriteCollections, DeepEquals, []string{"@@orders"})
+	query, err = reg.Query("deactivate_users")
+	c.Assert(err, IsNil)
+	c.Assert(query.WriteCollections, DeepEquals, []string{"users"})
+	_, err = reg.Query("find_inactive_users")
+	c.Assert(errors.Is(err, ErrUnknownQuery), Equals, true)
+	parser := &testParser{}
+	c.Assert(reg.Validate(parser), IsNil)
+	c.Assert(len(parser.parsed), Equals, 3)
+}
+
+func (s *RegistrySuite) TestLoadInvalid(c *C) {
+	invalid := []fstest.MapFS{
+		{"q/empty.aql": {Data: []byte("// @batchSize 10\n\n")}},
+		{"q/batch.aql": {Data: []byte("// @batchSize ten\nRETURN 1")}},
+		{"q/kind.aql": {Data: []byte("// @kind truncate\nRETURN 1")}},
+		{"q/annotation.aql": {Data: []byte("// @timeout 10\nRETURN 1")}},
+		{"q/a/dup.aql": {Data: []byte("RETURN 1")}, "q/b/dup.aql": {Data: []byte("RETURN 2")}},
+		{"q/read.aql": {Data: []byte("// @write users\nFOR u IN users RETURN u")}},
+		{"q/undeclared.aql": {Data: []byte("// @kind remove\nFOR u IN users REMOVE u IN users")}},
+		{"q/param.aql": {Data: []byte("// @kind insert\n// @write @@orders\nINSERT @order INTO orders")}},
+	}
+	for _, fsys := range invalid {
+		reg, err := Load(fsys, "q")
+		c.Assert(errors.Is(err, ErrInvalidQuery), Equals, true)
+		c.Assert(reg, IsNil)
+	}
+	_, err := Load(fstest.MapFS{}, "missing")
+	c.Assert(err, NotNil)
+	// Syntax errors are only caught when validating with the service.
+	reg, err := Load(fstest.MapFS{"q/broken.aql": {Data: []byte("RETRUN 1")}}, "q")
+	c.Assert(err, IsNil)
+	err = reg.Validate(&testParser{})
+	c.Assert(err, ErrorMatches, "Failed to parse query broken: syntax error")
+}
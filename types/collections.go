@@ -0,0 +1,249 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// CollectionType is the type of a collection, either a document or an edge collection.
+type CollectionType int
+
+const (
+	// CollectionTypeDocument is the type of collections holding documents.
+	CollectionTypeDocument CollectionType = 2
+	// CollectionTypeEdge is the type of collections holding the edges of a graph.
+	CollectionTypeEdge CollectionType = 3
+)
+
+// CollectionStatus is the status of a collection in the data store.
+type CollectionStatus int
+
+const (
+	// CollectionUnloaded is the status of a collection that isn't loaded into memory.
+	CollectionUnloaded CollectionStatus = 2
+	// CollectionLoaded is the status of a collection that is loaded into memory.
+	CollectionLoaded CollectionStatus = 3
+	// CollectionUnloading is the status of a collection that is being unloaded.
+	CollectionUnloading CollectionStatus = 4
+	// CollectionDeleted is the status of a collection that has been dropped.
+	CollectionDeleted CollectionStatus = 5
+	// CollectionLoading is the status of a collection that is being loaded.
+	CollectionLoading CollectionStatus = 6
+)
+
+// CollectionInfo provides the basic information about a collection.
+type CollectionInfo struct {
+	ID       string           `json:"id"`
+	Name     string           `json:"name"`
+	Type     CollectionType   `json:"type"`
+	Status   CollectionStatus `json:"status"`
+	IsSystem bool             `json:"isSystem"`
+}
+
+// CollectionProperties provides the information about a collection along with its settings,
+// JournalSize is in bytes and Schema is nil when the collection has no schema.
+type CollectionProperties struct {
+	CollectionInfo
+	WaitForSync bool                  `json:"waitForSync"`
+	JournalSize int64                 `json:"journalSize"`
+	Schema      *CollectionSchema     `json:"schema"`
+	KeyOptions  *CollectionKeyOptions `json:"keyOptions,omitempty"`
+	// The sharding settings are only provided by a cluster.
+	NumberOfShards    int      `json:"numberOfShards,omitempty"`
+	ShardKeys         []string `json:"shardKeys,omitempty"`
+	ReplicationFactor int      `json:"replicationFactor,omitempty"`
+}
+
+// CollectionPropertiesUpdate provides the settings to change on a collection,
+// settings that are nil or zero are left unchanged.
+type CollectionPropertiesUpdate struct {
+	WaitForSync *bool             `json:"waitForSync,omitempty"`
+	JournalSize int64             `json:"journalSize,omitempty"`
+	Schema      *CollectionSchema `json:"schema,omitempty"`
+}
+
+// CollectionSchema provides the JSON Schema documents in a collection are validated against,
+// Level decides which documents are validated and Message is the error message used when a
+// document fails validation.
+type CollectionSchema struct {
+	Rule    json.RawMessage `json:"rule"`
+	Level   SchemaLevel     `json:"level"`
+	Message string          `json:"message"`
+}
+
+// CollectionFigures provides the storage figures of a collection, sizes are in bytes.
+type CollectionFigures struct {
+	DocumentsSize int64 `json:"documentsSize"`
+	Indexes       struct {
+		Count int64 `json:"count"`
+		Size  int64 `json:"size"`
+	} `json:"indexes"`
+	CacheInUse bool  `json:"cacheInUse"`
+	CacheSize  int64 `json:"cacheSize"`
+	CacheUsage int64 `json:"cacheUsage"`
+}
+
+// CollectionListResult provides the response result for listing the collections of the database.
+type CollectionListResult struct {
+	Err         error
+	StatusCode  int
+	Message     string
+	Collections []*CollectionInfo
+}
+
+// CollectionPropertiesResult provides the response result for retrieving
+// or updating the properties of a collection.
+type CollectionPropertiesResult struct {
+	Err        error
+	StatusCode int
+	Message    string
+	Properties *CollectionProperties
+}
+
+// CollectionFiguresResult provides the response result for retrieving the figures of a collection,
+// Count is the number of documents in the collection.
+type CollectionFiguresResult struct {
+	Err        error
+	StatusCode int
+	Message    string
+	Count      int64
+	Figures    *CollectionFigures
+}
+
+// CollectionOpResult provides the response result for operations on a collection
+// such as dropping, truncating, renaming, loading or unloading it. Collection holds
+// the information about the collection after the operation.
+type CollectionOpResult struct {
+	Err        error
+	StatusCode int
+	Message    string
+	Collection *CollectionInfo
+}
+
+// KeyGenerator is the generator used for the keys of new documents in a collection.
+type KeyGenerator string
+
+const (
+	// KeyGeneratorTraditional generates ascending numeric keys, it is the default generator.
+	KeyGeneratorTraditional KeyGenerator = "traditional"
+	// KeyGeneratorAutoIncrement generates numeric keys from an offset and an increment.
+	KeyGeneratorAutoIncrement KeyGenerator = "autoincrement"
+	// KeyGeneratorUUID generates universally unique keys.
+	KeyGeneratorUUID KeyGenerator = "uuid"
+	// KeyGeneratorPadded generates fixed length keys that sort in ascending order.
+	KeyGeneratorPadded KeyGenerator = "padded"
+)
+
+// Valid determines whether the key generator is one of the known generators.
+func (g KeyGenerator) Valid() bool {
+	switch g {
+	case KeyGeneratorTraditional, KeyGeneratorAutoIncrement, KeyGeneratorUUID, KeyGeneratorPadded:
+		return true
+	}
+	return false
+}
+
+// CollectionKeyOptions provides the settings for the keys of the documents in a collection,
+// Increment and Offset are only used by the autoincrement generator. AllowUserKeys is
+// left to the data store default of true when nil.
+type CollectionKeyOptions struct {
+	Type          KeyGenerator `json:"type,omitempty"`
+	AllowUserKeys *bool        `json:"allowUserKeys,omitempty"`
+	Increment     int          `json:"increment,omitempty"`
+	Offset        int          `json:"offset,omitempty"`
+}
+
+// ErrInvalidCollectionOptions is the error returned when the options for
+// creating a collection are invalid or can't be used together.
+var ErrInvalidCollectionOptions = errors.New("The provided collection options are invalid")
+
+// CollectionOptions provides the settings for creating a collection, a zero Type creates
+// a document collection and the sharding settings are only used by a cluster.
+type CollectionOptions struct {
+	Type              CollectionType        `json:"type,omitempty"`
+	WaitForSync       bool                  `json:"waitForSync,omitempty"`
+	KeyOptions        *CollectionKeyOptions `json:"keyOptions,omitempty"`
+	NumberOfShards    int                   `json:"numberOfShards,omitempty"`
+	ShardKeys         []string              `json:"shardKeys,omitempty"`
+	ReplicationFactor int                   `json:"replicationFactor,omitempty"`
+	Schema            *CollectionSchema     `json:"schema,omitempty"`
+}
+
+// Validate ensures the collection options are valid and don't combine settings
+// the data store would reject, so the request doesn't need to be made.
+func (o *CollectionOptions) Validate() error {
+	if o.Type != 0 && o.Type != CollectionTypeDocument && o.Type != CollectionTypeEdge {
+		return fmt.Errorf("%w: unknown collection type %d", ErrInvalidCollectionOptions, o.Type)
+	}
+	if o.NumberOfShards < 0 || o.ReplicationFactor < 0 {
+		return fmt.Errorf("%w: the number of shards and replication factor can't be negative",
+			ErrInvalidCollectionOptions)
+	}
+	if err := o.validateShardKeys(); err != nil {
+		return err
+	}
+	if err := o.validateKeyOptions(); err != nil {
+		return err
+	}
+	if o.Schema != nil {
+		if err := o.Schema.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCollectionOptions, err)
+		}
+	}
+	return nil
+}
+
+func (o *CollectionOptions) validateShardKeys() error {
+	seen := make(map[string]bool)
+	for _, key := range o.ShardKeys {
+		if key == "" {
+			return fmt.Errorf("%w: empty shard key", ErrInvalidCollectionOptions)
+		}
+		if seen[key] {
+			return fmt.Errorf("%w: duplicate shard key %q", ErrInvalidCollectionOptions, key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Whether the documents are distributed by attributes other than their keys.
+func (o *CollectionOptions) customShardKeys() bool {
+	return len(o.ShardKeys) > 0 && !(len(o.ShardKeys) == 1 && o.ShardKeys[0] == "_key")
+}
+
+func (o *CollectionOptions) validateKeyOptions() error {
+	keyOpts := o.KeyOptions
+	if keyOpts == nil {
+		return nil
+	}
+	if keyOpts.Type != "" && !keyOpts.Type.Valid() {
+		return fmt.Errorf("%w: unknown key generator %q", ErrInvalidCollectionOptions, keyOpts.Type)
+	}
+	if keyOpts.Type != KeyGeneratorAutoIncrement && (keyOpts.Increment != 0 || keyOpts.Offset != 0) {
+		return fmt.Errorf("%w: increment and offset are only used by the autoincrement key generator",
+			ErrInvalidCollectionOptions)
+	}
+	if keyOpts.Increment < 0 || keyOpts.Offset < 0 {
+		return fmt.Errorf("%w: increment and offset can't be negative", ErrInvalidCollectionOptions)
+	}
+	if keyOpts.Type == KeyGeneratorAutoIncrement && o.NumberOfShards > 1 {
+		return fmt.Errorf("%w: the autoincrement key generator can't be used with more than one shard",
+			ErrInvalidCollectionOptions)
+	}
+	if keyOpts.AllowUserKeys != nil && *keyOpts.AllowUserKeys && o.customShardKeys() {
+		return fmt.Errorf("%w: user keys can't be allowed when sharding by attributes other than _key",
+			ErrInvalidCollectionOptions)
+	}
+	return nil
+}
+
+// CollectionSchemaResult provides the response result for retrieving or changing the schema
+// of a collection, Schema is nil when the collection has no schema.
+type CollectionSchemaResult struct {
+	Err        error
+	StatusCode int
+	Message    string
+	Schema     *CollectionSchema
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventOp is the kind of operation on a document that produced an event.
+type EventOp string
+
+const (
+	// EventInsert is the operation for events produced by inserting a new document.
+	EventInsert EventOp = "insert"
+	// EventUpdate is the operation for events produced by partially updating a document.
+	EventUpdate EventOp = "update"
+	// EventReplace is the operation for events produced by replacing a document.
+	EventReplace EventOp = "replace"
+	// EventRemove is the operation for events produced by removing a document.
+	EventRemove EventOp = "remove"
+)
+
+// Event provides the data structure for the events produced by the microfoxx service
+// for every operation on a document. OldRev is empty for inserts and NewRev is empty
+// for removals, Created is the time the event was produced in milliseconds since the
+// unix epoch and Data holds the raw JSON payload of the event.
+// Tick is the position of the event in the change feed of its collection,
+// it is only set for events received through a subscription.
+type Event struct {
+	Tick       string          `json:"tick,omitempty"`
+	Type       string          `json:"type"`
+	Op         EventOp         `json:"op"`
+	Collection string          `json:"collection"`
+	Key        string          `json:"key"`
+	OldRev     string          `json:"oldRev"`
+	NewRev     string          `json:"newRev"`
+	Created    int64           `json:"created"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// CreatedAt provides the time the event was produced.
+func (e *Event) CreatedAt() time.Time {
+	return time.Unix(0, e.Created*int64(time.Millisecond))
+}
+
+// SubscribeOptions are the options used to control how a subscription
+// to the change feed of a collection retrieves events.
+type SubscribeOptions struct {
+	// BufferSize is the amount of events held for the subscriber before
+	// polling for more events is paused, defaults to 100.
+	BufferSize int
+	// PollTimeout is how long the service waits for new events before responding
+	// to each poll without any, defaults to 5 seconds and must be shorter than
+	// the timeout of the HTTP client.
+	PollTimeout time.Duration
+	// MinBackoff is the delay before reconnecting after the first failed poll, it doubles
+	// for each consecutive failure up to MaxBackoff. Defaults to 100 milliseconds and 10 seconds,
+	// or MinBackoff when it is longer than 10 seconds.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
@@ -0,0 +1,140 @@
+package types
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExplainResult provides the response result for explaining a query,
+// Plan is the execution plan chosen by the optimizer.
+type ExplainResult struct {
+	Err        error
+	StatusCode int
+	Message    string
+	Plan       *Plan
+	Cacheable  bool
+	Warnings   []*QueryWarning
+}
+
+// ProfileResult provides the response result for profiling a query, the query is run
+// to completion and every node of the plan provides its runtime statistics.
+// Phases provides the time spent in each phase of running the query in seconds.
+type ProfileResult struct {
+	Err           error
+	StatusCode    int
+	Message       string
+	Documents     io.Reader
+	Plan          *Plan
+	Phases        map[string]float64
+	ExecutionTime float64
+	Warnings      []*QueryWarning
+}
+
+// Plan provides the execution plan for a query, the nodes are ordered
+// so every node comes after the nodes it depends on.
+type Plan struct {
+	Nodes            []*PlanNode       `json:"nodes"`
+	Rules            []string          `json:"rules"`
+	Collections      []*PlanCollection `json:"collections"`
+	EstimatedCost    float64           `json:"estimatedCost"`
+	EstimatedNrItems int               `json:"estimatedNrItems"`
+}
+
+// PlanNode provides a single step of an execution plan, such as a collection
+// scan, an index lookup, a filter or a sort. Dependencies are the identifiers of the
+// nodes providing the input of the node. Stats is only set for profiled queries.
+type PlanNode struct {
+	ID               int            `json:"id"`
+	Type             string         `json:"type"`
+	Dependencies     []int          `json:"dependencies"`
+	EstimatedCost    float64        `json:"estimatedCost"`
+	EstimatedNrItems int            `json:"estimatedNrItems"`
+	Collection       string         `json:"collection,omitempty"`
+	Indexes          []*PlanIndex   `json:"indexes,omitempty"`
+	Stats            *PlanNodeStats `json:"stats,omitempty"`
+}
+
+// PlanIndex provides an index used by a node of an execution plan.
+type PlanIndex struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Fields      []string `json:"fields"`
+	Unique      bool     `json:"unique"`
+	Sparse      bool     `json:"sparse"`
+	Selectivity float64  `json:"selectivityEstimate"`
+}
+
+// PlanCollection provides a collection used by an execution plan
+// and whether it is read from or written to.
+type PlanCollection struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// PlanNodeStats provides the runtime statistics of a node of a profiled query,
+// Runtime is the time spent in the node and its dependencies in seconds.
+type PlanNodeStats struct {
+	Calls   int     `json:"calls"`
+	Items   int     `json:"items"`
+	Runtime float64 `json:"runtime"`
+}
+
+// String renders the plan as a text tree starting from the node producing the
+// results of the query, followed by the optimizer rules applied to the plan.
+// A nil plan renders as an empty string.
+func (p *Plan) String() string {
+	if p == nil {
+		return ""
+	}
+	nodes := make(map[int]*PlanNode, len(p.Nodes))
+	dependedOn := make(map[int]bool)
+	for _, node := range p.Nodes {
+		nodes[node.ID] = node
+		for _, dep := range node.Dependencies {
+			dependedOn[dep] = true
+		}
+	}
+	var b strings.Builder
+	for _, node := range p.Nodes {
+		if !dependedOn[node.ID] {
+			writePlanNode(&b, nodes, node, "", "")
+		}
+	}
+	if len(p.Rules) > 0 {
+		b.WriteString("Optimizer rules applied: " + strings.Join(p.Rules, ", ") + "\n")
+	}
+	return b.String()
+}
+
+// Deals with writing the node and then each of its dependencies indented
+// beneath it, prefix is written before the node and indent before its children.
+func writePlanNode(b *strings.Builder, nodes map[int]*PlanNode, node *PlanNode, prefix string, indent string) {
+	b.WriteString(prefix + node.Type + " #" + strconv.Itoa(node.ID))
+	if node.Collection != "" {
+		b.WriteString(" " + node.Collection)
+	}
+	for _, index := range node.Indexes {
+		b.WriteString(" [" + index.Type + " index on " + strings.Join(index.Fields, ", ") + "]")
+	}
+	b.WriteString(" (cost " + strconv.FormatFloat(node.EstimatedCost, 'f', -1, 64) +
+		", " + strconv.Itoa(node.EstimatedNrItems) + " items")
+	if node.Stats != nil {
+		b.WriteString(", " + strconv.Itoa(node.Stats.Calls) + " calls, " +
+			strconv.Itoa(node.Stats.Items) + " actual items, " +
+			strconv.FormatFloat(node.Stats.Runtime, 'f', -1, 64) + "s")
+	}
+	b.WriteString(")\n")
+	for i, dep := range node.Dependencies {
+		child, ok := nodes[dep]
+		if !ok {
+			continue
+		}
+		if i == len(node.Dependencies)-1 {
+			writePlanNode(b, nodes, child, indent+"└─ ", indent+"   ")
+		} else {
+			writePlanNode(b, nodes, child, indent+"├─ ", indent+"│  ")
+		}
+	}
+}
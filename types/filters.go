@@ -0,0 +1,171 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// FilterOp is the operator applied by a filter expression.
+type FilterOp string
+
+const (
+	// FilterEq matches documents where the field equals the value.
+	// An Eq filter with a nil value matches documents where the field is null.
+	FilterEq FilterOp = "eq"
+	// FilterNe matches documents where the field does not equal the value.
+	FilterNe FilterOp = "ne"
+	// FilterLt matches documents where the field is less than the value.
+	FilterLt FilterOp = "lt"
+	// FilterLte matches documents where the field is less than or equal to the value.
+	FilterLte FilterOp = "lte"
+	// FilterGt matches documents where the field is greater than the value.
+	FilterGt FilterOp = "gt"
+	// FilterGte matches documents where the field is greater than or equal to the value.
+	FilterGte FilterOp = "gte"
+	// FilterIn matches documents where the field equals one of the values in a list.
+	FilterIn FilterOp = "in"
+	// FilterLike matches documents where the field matches an AQL LIKE pattern,
+	// % matches any sequence of characters and _ matches a single character.
+	FilterLike FilterOp = "like"
+	// FilterExists matches documents where the field is present and not null,
+	// or where it is missing or null when the value is false.
+	FilterExists FilterOp = "exists"
+	// FilterAnd matches documents that match all of the sub filters.
+	FilterAnd FilterOp = "and"
+	// FilterOr matches documents that match at least one of the sub filters.
+	FilterOr FilterOp = "or"
+)
+
+var (
+	// ErrInvalidFilter is the error returned when a filter expression
+	// can't be encoded into a valid request for the service.
+	ErrInvalidFilter = errors.New("The provided filter expression is invalid")
+
+	// Field paths are made up of one or more attribute names separated by dots
+	// so that filters can be applied to nested attributes.
+	fieldPathRegExp = regexp.MustCompile("^[\\w\\-]+(\\.[\\w\\-]+)*$")
+)
+
+// Filter provides the data structure for a filter expression used to narrow down
+// the documents returned from a collection.
+//
+// Filters are sent to the service as JSON in the filter query string parameter, comparison
+// filters take the form {"op":"gte","field":"address.floor","value":3} and logical filters
+// take the form {"op":"and","filters":[...]}.
+type Filter struct {
+	Op      FilterOp    `json:"op"`
+	Field   string      `json:"field,omitempty"`
+	Value   interface{} `json:"value"`
+	Filters []*Filter   `json:"filters,omitempty"`
+}
+
+// Eq creates a filter matching documents where the field equals the value.
+func Eq(field string, value interface{}) *Filter {
+	return &Filter{Op: FilterEq, Field: field, Value: value}
+}
+
+// Ne creates a filter matching documents where the field does not equal the value.
+func Ne(field string, value interface{}) *Filter {
+	return &Filter{Op: FilterNe, Field: field, Value: value}
+}
+
+// Lt creates a filter matching documents where the field is less than the value.
+func Lt(field string, value interface{}) *Filter {
+	return &Filter{Op: FilterLt, Field: field, Value: value}
+}
+
+// Lte creates a filter matching documents where the field is less than or equal to the value.
+func Lte(field string, value interface{}) *Filter {
+	return &Filter{Op: FilterLte, Field: field, Value: value}
+}
+
+// Gt creates a filter matching documents where the field is greater than the value.
+func Gt(field string, value interface{}) *Filter {
+	return &Filter{Op: FilterGt, Field: field, Value: value}
+}
+
+// Gte creates a filter matching documents where the field is greater than or equal to the value.
+func Gte(field string, value interface{}) *Filter {
+	return &Filter{Op: FilterGte, Field: field, Value: value}
+}
+
+// In creates a filter matching documents where the field equals one of the provided values.
+func In(field string, values ...interface{}) *Filter {
+	return &Filter{Op: FilterIn, Field: field, Value: values}
+}
+
+// Like creates a filter matching documents where the field matches the provided pattern.
+func Like(field string, pattern string) *Filter {
+	return &Filter{Op: FilterLike, Field: field, Value: pattern}
+}
+
+// Exists creates a filter matching documents where the field is set to a non-null value,
+// or where the field is missing or null when exists is false.
+func Exists(field string, exists bool) *Filter {
+	return &Filter{Op: FilterExists, Field: field, Value: exists}
+}
+
+// And creates a filter matching documents that match all of the provided filters.
+func And(filters ...*Filter) *Filter {
+	return &Filter{Op: FilterAnd, Filters: filters}
+}
+
+// Or creates a filter matching documents that match any of the provided filters.
+func Or(filters ...*Filter) *Filter {
+	return &Filter{Op: FilterOr, Filters: filters}
+}
+
+// Validate ensures the filter and all of its sub filters are well formed
+// before they are sent to the service.
+func (f *Filter) Validate() error {
+	if f == nil {
+		return fmt.Errorf("%w: nil filter", ErrInvalidFilter)
+	}
+	switch f.Op {
+	case FilterAnd, FilterOr:
+		if f.Field != "" {
+			return fmt.Errorf("%w: %s filters can't have a field", ErrInvalidFilter, f.Op)
+		}
+		if len(f.Filters) == 0 {
+			return fmt.Errorf("%w: %s filters need at least one sub filter", ErrInvalidFilter, f.Op)
+		}
+		for _, sub := range f.Filters {
+			if err := sub.Validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FilterEq, FilterNe, FilterLt, FilterLte, FilterGt, FilterGte, FilterIn, FilterLike, FilterExists:
+		// Comparison filters are checked below.
+	default:
+		return fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, f.Op)
+	}
+	if !fieldPathRegExp.MatchString(f.Field) {
+		return fmt.Errorf("%w: invalid field path %q", ErrInvalidFilter, f.Field)
+	}
+	if len(f.Filters) > 0 {
+		return fmt.Errorf("%w: %s filters can't have sub filters", ErrInvalidFilter, f.Op)
+	}
+	switch f.Op {
+	case FilterIn:
+		v := reflect.ValueOf(f.Value)
+		if f.Value == nil || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+			return fmt.Errorf("%w: in filter on %q needs a list of values", ErrInvalidFilter, f.Field)
+		}
+	case FilterLike:
+		if _, ok := f.Value.(string); !ok {
+			return fmt.Errorf("%w: like filter on %q needs a string pattern", ErrInvalidFilter, f.Field)
+		}
+	case FilterExists:
+		if _, ok := f.Value.(bool); !ok {
+			return fmt.Errorf("%w: exists filter on %q needs a boolean value", ErrInvalidFilter, f.Field)
+		}
+	case FilterLt, FilterLte, FilterGt, FilterGte:
+		if f.Value == nil {
+			return fmt.Errorf("%w: %s filter on %q can't compare against null", ErrInvalidFilter, f.Op, f.Field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrBindVars is the error returned when the bind variables of a query
+// don't match the bind parameters used in the query.
+var ErrBindVars = errors.New("The bind variables don't match the bind parameters of the query")
+
+// ParseResult provides the response result for parsing a query without running it,
+// Collections are the collections referenced by the query and BindVars the names of
+// the bind parameters it requires, collection bind parameters are prefixed with @.
+type ParseResult struct {
+	Err         error
+	StatusCode  int
+	Message     string
+	Collections []string
+	BindVars    []string
+}
+
+// QueryBindParams provides the names of the bind parameters used in the query in the order
+// they first appear, collection bind parameters such as @@coll are provided as "@coll".
+// Parameters in string literals, quoted names and comments are ignored.
+func QueryBindParams(query string) []string {
+	params := make([]string, 0)
+	seen := make(map[string]bool)
+	for i := 0; i < len(query); i++ {
+		switch ch := query[i]; {
+		case ch == '\'' || ch == '"' || ch == '`':
+			i = skipQuoted(query, i, ch)
+		case strings.HasPrefix(query[i:], "//"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return params
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return params
+			}
+			i += end + 3
+		case ch == '@':
+			start := i + 1
+			if start < len(query) && query[start] == '@' {
+				start++
+			}
+			end := start
+			for end < len(query) && isBindParamChar(query[end]) {
+				end++
+			}
+			if end > start {
+				name := query[i+1 : end]
+				if !seen[name] {
+					seen[name] = true
+					params = append(params, name)
+				}
+			}
+			i = end - 1
+		}
+	}
+	return params
+}
+
+// Deals with finding the position of the quote closing the quoted
+// section starting at the provided position, escaped quotes are skipped.
+func skipQuoted(query string, start int, quote byte) int {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] == '\\' {
+			i++
+		} else if query[i] == quote {
+			return i
+		}
+	}
+	return len(query)
+}
+
+// The kinds of token the writes of a query are found from.
+const (
+	tokenWord = iota
+	tokenName
+	tokenBindParam
+	tokenValue
+	tokenPunct
+)
+
+// A token of an AQL query, the text of quoted names is provided without the backticks.
+type queryToken struct {
+	kind int
+	text string
+}
+
+// Deals with splitting the query into the words, names, bind parameters and brackets
+// needed to follow its structure, string literals are provided as values without
+// their text and comments, operators and whitespace are left out.
+func queryTokens(query string) []queryToken {
+	tokens := make([]queryToken, 0)
+	for i := 0; i < len(query); i++ {
+		switch ch := query[i]; {
+		case ch == '\'' || ch == '"':
+			i = skipQuoted(query, i, ch)
+			tokens = append(tokens, queryToken{kind: tokenValue})
+		case ch == '`':
+			end := skipQuoted(query, i, ch)
+			tokens = append(tokens, queryToken{kind: tokenName, text: query[i+1 : end]})
+			i = end
+		case strings.HasPrefix(query[i:], "//"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 3
+		case ch == '@' || isBindParamChar(ch):
+			end := i + 1
+			if ch == '@' && end < len(query) && query[end] == '@' {
+				end++
+			}
+			for end < len(query) && isBindParamChar(query[end]) {
+				end++
+			}
+			kind := tokenWord
+			if ch == '@' {
+				kind = tokenBindParam
+			}
+			tokens = append(tokens, queryToken{kind: kind, text: query[i:end]})
+			i = end - 1
+		case strings.IndexByte("()[]{}.:", ch) >= 0:
+			tokens = append(tokens, queryToken{kind: tokenPunct, text: query[i : i+1]})
+		}
+	}
+	return tokens
+}
+
+func isBindParamChar(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// Validate ensures the bind variables provide exactly the bind parameters used in the query,
+// the error lists the missing parameters and the bind variables the query doesn't use.
+func (p *CursorQueryParams) Validate() error {
+	required := QueryBindParams(p.Query)
+	missing := make([]string, 0)
+	used := make(map[string]bool, len(required))
+	for _, name := range required {
+		used[name] = true
+		if _, ok := p.BindVars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	unused := make([]string, 0)
+	for name := range p.BindVars {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(missing) == 0 && len(unused) == 0 {
+		return nil
+	}
+	sort.Strings(unused)
+	problems := make([]string, 0, 2)
+	if len(missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(missing, ", "))
+	}
+	if len(unused) > 0 {
+		problems = append(problems, "unused "+strings.Join(unused, ", "))
+	}
+	return fmt.Errorf("%w: %s", ErrBindVars, strings.Join(problems, "; "))
+}
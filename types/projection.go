@@ -0,0 +1,26 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidProjection is the error returned when the attributes
+// requested for a projection are invalid.
+var ErrInvalidProjection = errors.New("The provided projection is invalid")
+
+// ValidateProjection ensures each of the provided fields is a valid attribute name
+// or dot separated path to a nested attribute and that no field is repeated.
+func ValidateProjection(fields []string) error {
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !fieldPathRegExp.MatchString(field) {
+			return fmt.Errorf("%w: invalid field path %q", ErrInvalidProjection, field)
+		}
+		if seen[field] {
+			return fmt.Errorf("%w: field %q is repeated", ErrInvalidProjection, field)
+		}
+		seen[field] = true
+	}
+	return nil
+}
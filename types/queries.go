@@ -0,0 +1,134 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// QueryKind is the kind of modification carried out by a modifying query,
+// it decides which of the modification query endpoints the query is sent to.
+type QueryKind string
+
+const (
+	// QueryInsert is the kind of queries that insert new documents.
+	QueryInsert QueryKind = "insert"
+	// QueryUpdate is the kind of queries that partially update existing documents.
+	QueryUpdate QueryKind = "update"
+	// QueryReplace is the kind of queries that replace existing documents.
+	QueryReplace QueryKind = "replace"
+	// QueryRemove is the kind of queries that remove existing documents.
+	QueryRemove QueryKind = "remove"
+	// QueryUpsert is the kind of queries that update documents when they exist
+	// and insert them otherwise.
+	QueryUpsert QueryKind = "upsert"
+)
+
+// Valid determines whether the kind is one of the supported kinds of modifying query.
+func (k QueryKind) Valid() bool {
+	switch k {
+	case QueryInsert, QueryUpdate, QueryReplace, QueryRemove, QueryUpsert:
+		return true
+	}
+	return false
+}
+
+// ErrUndeclaredCollection is the error returned when a modifying query writes
+// to a collection that hasn't been declared as a write or exclusive collection.
+var ErrUndeclaredCollection = errors.New("The query writes to a collection that hasn't been declared")
+
+// AllWriteCollections provides every collection declared for writing, the single
+// WriteCollection is included for compatibility with params that only set it.
+func (p *ModifyingQueryParams) AllWriteCollections() []string {
+	colls := make([]string, 0, len(p.WriteCollections)+1)
+	seen := make(map[string]bool)
+	for _, coll := range append([]string{p.WriteCollection}, p.WriteCollections...) {
+		if coll != "" && !seen[coll] {
+			seen[coll] = true
+			colls = append(colls, coll)
+		}
+	}
+	return colls
+}
+
+// WriteTargets provides the collections the query writes to, which are the collections
+// following the IN or INTO that ends each INSERT, UPDATE, REPLACE, REMOVE or UPSERT
+// operation. The IN operator within the expressions of an operation is told apart by
+// the brackets around it. Collections provided through bind parameters are resolved
+// from the bind variables and skipped when the bind variable is missing.
+func (p *ModifyingQueryParams) WriteTargets() []string {
+	targets := make([]string, 0)
+	seen := make(map[string]bool)
+	tokens := queryTokens(p.Query)
+	// The operation waiting for its collection at each bracket depth.
+	pending := make(map[int]string)
+	depth := 0
+	for i, tok := range tokens {
+		if tok.kind == tokenPunct {
+			switch tok.text {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				delete(pending, depth)
+				depth--
+			}
+			continue
+		}
+		if tok.kind != tokenWord || isAttribute(tokens, i) {
+			continue
+		}
+		switch keyword := strings.ToUpper(tok.text); keyword {
+		case "INSERT", "UPDATE", "REPLACE", "REMOVE", "UPSERT":
+			// The INSERT and UPDATE or REPLACE of an upsert are part of the same operation.
+			if pending[depth] != "UPSERT" || keyword == "UPSERT" || keyword == "REMOVE" {
+				pending[depth] = keyword
+			}
+		case "IN", "INTO":
+			if _, ok := pending[depth]; !ok || i+1 >= len(tokens) {
+				continue
+			}
+			delete(pending, depth)
+			target := tokens[i+1]
+			if target.kind == tokenBindParam && strings.HasPrefix(target.text, "@@") {
+				name, ok := p.BindVars[strings.TrimPrefix(target.text, "@")].(string)
+				if !ok {
+					continue
+				}
+				target.text = name
+			} else if target.kind != tokenWord && target.kind != tokenName {
+				continue
+			}
+			if !seen[target.text] {
+				seen[target.text] = true
+				targets = append(targets, target.text)
+			}
+		}
+	}
+	return targets
+}
+
+// Whether the word at the provided position is an attribute name rather than
+// a keyword, such as in doc.update or { remove: true }.
+func isAttribute(tokens []queryToken, i int) bool {
+	return (i > 0 && tokens[i-1].kind == tokenPunct && tokens[i-1].text == ".") ||
+		(i+1 < len(tokens) && tokens[i+1].kind == tokenPunct && tokens[i+1].text == ":")
+}
+
+// Validate ensures every collection the query writes to has been declared as
+// a write or exclusive collection. Params without any declared collections are
+// left for the service to validate.
+func (p *ModifyingQueryParams) Validate() error {
+	declared := make(map[string]bool)
+	for _, coll := range append(p.AllWriteCollections(), p.ExclusiveCollections...) {
+		declared[coll] = true
+	}
+	if len(declared) == 0 {
+		return nil
+	}
+	for _, target := range p.WriteTargets() {
+		if !declared[target] {
+			return fmt.Errorf("%w: %s", ErrUndeclaredCollection, target)
+		}
+	}
+	return nil
+}
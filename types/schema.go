@@ -0,0 +1,181 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SchemaLevel decides which documents of a collection are validated against its schema.
+type SchemaLevel string
+
+const (
+	// SchemaLevelNone disables validation while keeping the schema on the collection.
+	SchemaLevelNone SchemaLevel = "none"
+	// SchemaLevelNew only validates newly inserted documents.
+	SchemaLevelNew SchemaLevel = "new"
+	// SchemaLevelModerate validates new documents and changed documents that were valid before the change.
+	SchemaLevelModerate SchemaLevel = "moderate"
+	// SchemaLevelStrict validates every new and changed document.
+	SchemaLevelStrict SchemaLevel = "strict"
+)
+
+// Valid determines whether the schema level is one of the known levels.
+func (l SchemaLevel) Valid() bool {
+	switch l {
+	case SchemaLevelNone, SchemaLevelNew, SchemaLevelModerate, SchemaLevelStrict:
+		return true
+	}
+	return false
+}
+
+// ErrInvalidSchema is the error returned when a collection schema is invalid
+// or a JSON Schema can't be derived from the provided value.
+var ErrInvalidSchema = errors.New("The provided collection schema is invalid")
+
+// Validate ensures the schema has a rule that is a JSON object and a known level,
+// an empty level is left to the data store default of strict.
+func (s *CollectionSchema) Validate() error {
+	var rule map[string]interface{}
+	if len(s.Rule) == 0 || json.Unmarshal(s.Rule, &rule) != nil || rule == nil {
+		return fmt.Errorf("%w: the rule must be a JSON object", ErrInvalidSchema)
+	}
+	if s.Level != "" && !s.Level.Valid() {
+		return fmt.Errorf("%w: unknown level %q", ErrInvalidSchema, s.Level)
+	}
+	return nil
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// SchemaFromStruct derives a JSON Schema rule from the fields of the provided struct
+// following the same json tags as encoding/json. Fields tagged with omitempty and pointer
+// fields are optional, every other field is required. Pointer, slice and map fields without
+// omitempty also accept null as that is how encoding/json provides them when they are nil,
+// so the struct's own encoding always matches the rule. Additional properties are allowed
+// so documents can still carry the attributes set by the data store such as _key and _rev.
+func SchemaFromStruct(v interface{}) (json.RawMessage, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: a schema can only be derived from a struct, not %v", ErrInvalidSchema, t)
+	}
+	return json.Marshal(typeSchema(t, make(map[reflect.Type]bool)))
+}
+
+// Whether nil values of the type are encoded as null by encoding/json.
+func nilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// Deals with deriving the schema of a type whose nil values are encoded as null,
+// so null is accepted along with the type.
+func nullableSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	schema := typeSchema(t, visiting)
+	if schemaType, ok := schema["type"].(string); ok && nilable(t) {
+		schema["type"] = []string{schemaType, "null"}
+	}
+	return schema
+}
+
+// Deals with deriving the schema of a single type, visiting holds the struct types
+// being derived so recursive types end in a plain object schema.
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]interface{}{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are encoded as base64 strings.
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "array", "items": nullableSchema(t.Elem(), visiting)}
+	case reflect.Map:
+		schema := map[string]interface{}{"type": "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			schema["additionalProperties"] = nullableSchema(t.Elem(), visiting)
+		}
+		return schema
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+		structProperties(t, visiting, properties, &required)
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	// Interfaces and any other kinds accept any value.
+	return map[string]interface{}{}
+}
+
+// Deals with adding the schema of each encoded field of the struct to properties,
+// the fields of embedded structs without a json name are promoted as encoding/json does.
+func structProperties(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]interface{},
+	required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			structProperties(fieldType, visiting, properties, required)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") {
+			// Nil values are left out rather than provided as null.
+			properties[name] = typeSchema(field.Type, visiting)
+			continue
+		}
+		properties[name] = nullableSchema(field.Type, visiting)
+		if field.Type.Kind() != reflect.Ptr {
+			*required = append(*required, name)
+		}
+	}
+}
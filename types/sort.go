@@ -0,0 +1,64 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SortDirection is the direction in which the documents are ordered by a sort field.
+type SortDirection string
+
+const (
+	// SortAsc orders documents from the lowest to the highest value.
+	SortAsc SortDirection = "ASC"
+	// SortDesc orders documents from the highest to the lowest value.
+	SortDesc SortDirection = "DESC"
+)
+
+// ErrInvalidSort is the error returned when the sort parameters for
+// a document retrieval request are invalid.
+var ErrInvalidSort = errors.New("The provided sort parameters are invalid")
+
+// SortField provides the field to sort on and the direction to sort in,
+// the field can be a dot separated path to a nested attribute.
+// An empty direction sorts in ascending order.
+type SortField struct {
+	Field     string
+	Direction SortDirection
+}
+
+// Asc creates a sort field ordering the provided field in ascending order.
+func Asc(field string) *SortField {
+	return &SortField{Field: field, Direction: SortAsc}
+}
+
+// Desc creates a sort field ordering the provided field in descending order.
+func Desc(field string) *SortField {
+	return &SortField{Field: field, Direction: SortDesc}
+}
+
+// Validate ensures the sort field refers to a valid field path
+// and a known direction.
+func (s *SortField) Validate() error {
+	if s == nil {
+		return fmt.Errorf("%w: nil sort field", ErrInvalidSort)
+	}
+	if !fieldPathRegExp.MatchString(s.Field) {
+		return fmt.Errorf("%w: invalid field path %q", ErrInvalidSort, s.Field)
+	}
+	_, err := ParseSortDirection(string(s.Direction))
+	return err
+}
+
+// ParseSortDirection converts the provided string into a sort direction,
+// the comparison is case-insensitive and an empty string is treated as ascending.
+func ParseSortDirection(direction string) (SortDirection, error) {
+	switch strings.ToUpper(direction) {
+	case "", string(SortAsc):
+		return SortAsc, nil
+	case string(SortDesc):
+		return SortDesc, nil
+	}
+	return "", fmt.Errorf("%w: unknown sort direction %q", ErrInvalidSort, direction)
+}
@@ -50,31 +50,64 @@ type DeleteResult struct {
 }
 
 // DocumentOpResult provides the response data relevant for an attempted operation
-// on a document in a collection.
+// on a document in a collection. Event holds the raw JSON event and DecodedEvent holds
+// the same event decoded into the event model, it is nil when the event doesn't match the model.
 type DocumentOpResult struct {
-	Err        error
-	StatusCode int
-	Message    string
-	Event      io.Reader
-	Document   io.Reader
+	Err          error
+	StatusCode   int
+	Message      string
+	Event        io.Reader
+	DecodedEvent *Event
+	Document     io.Reader
+}
+
+// DocumentUpsertResult provides the response data relevant for an attempted upsert
+// of a document in a collection, Inserted is true when no document matched the search
+// and a new document was created, otherwise the matched document was updated.
+type DocumentUpsertResult struct {
+	Err          error
+	StatusCode   int
+	Message      string
+	Inserted     bool
+	Event        io.Reader
+	DecodedEvent *Event
+	Document     io.Reader
 }
 
 // DocumentsOpResult provides the response data relevant for an attempted operation
 // on multiple documents in a collection through a modification AQL query.
+// DecodedEvents holds the events decoded into the event model, it is nil
+// when the events don't match the model. Old and New provide the documents before and
+// after the changes when ReturnOld and ReturnNew are set, they are nil otherwise.
 type DocumentsOpResult struct {
+	Err           error
+	StatusCode    int
+	Message       string
+	Events        io.Reader
+	DecodedEvents []*Event
+	Documents     io.Reader
+	Old           io.Reader
+	New           io.Reader
+}
+
+// DocumentsResult provides the response data used when running queries to retrieve multiple documents.
+type DocumentsResult struct {
 	Err        error
 	StatusCode int
 	Message    string
-	Events     io.Reader
 	Documents  io.Reader
 }
 
-// DocumentsResult provides the response data used when running queries to retrieve multiple documents.
-type DocumentsResult struct {
+// Page provides the response data for a single page of documents retrieved
+// with keyset pagination. NextToken is an opaque continuation token used to
+// retrieve the following page, it is empty when there are no more documents.
+type Page struct {
 	Err        error
 	StatusCode int
 	Message    string
 	Documents  io.Reader
+	Count      int
+	NextToken  string
 }
 
 // DocumentCountResult provides the data structure which is use to encapsulate the http response
@@ -95,26 +128,85 @@ type DocumentResult struct {
 	Document   io.Reader
 }
 
+// DocumentExistsResult provides the response data used when checking whether
+// a document exists without retrieving it, a missing document is not an error.
+type DocumentExistsResult struct {
+	Err        error
+	StatusCode int
+	Message    string
+	Exists     bool
+}
+
+// DocumentRevisionResult provides the response data used when retrieving the current
+// revision of a document without retrieving the document itself.
+type DocumentRevisionResult struct {
+	Err        error
+	StatusCode int
+	Message    string
+	Revision   string
+}
+
+// DocumentsExistResult provides the response data used when checking whether many
+// documents exist, Exists holds every key that was checked mapped to whether
+// a document with that key exists in the collection.
+type DocumentsExistResult struct {
+	Err        error
+	StatusCode int
+	Message    string
+	Exists     map[string]bool
+}
+
+// DocumentsByKeyResult provides the response data used when retrieving many documents
+// by their keys, Documents holds each document found keyed by its _key and Missing
+// holds the requested keys for which no document exists.
+type DocumentsByKeyResult struct {
+	Err        error
+	StatusCode int
+	Message    string
+	Documents  map[string]io.Reader
+	Missing    []string
+}
+
 // DocumentRetrievalParams are the parameters to be used to prepare a request to retrieve
 // a document from the data store.
+// Fields provides exact match filters on top level attributes, Filter can be used
+// for anything more expressive and is combined with Fields when both are set.
+// SortFields are all sorted in the single SortOrder direction, Sort can be used instead
+// to provide a direction for each field but the two can't be combined.
+// Return restricts the attributes of each document to the provided fields which can be
+// dot separated paths to nested attributes, the full documents are returned when empty.
 type DocumentRetrievalParams struct {
 	Fields      map[string]string
+	Filter      *Filter
 	SortFields  []string
 	SortOrder   string
+	Sort        []*SortField
+	Return      []string
 	LimitOffset int
 	LimitCount  int
 }
 
 // CursorQueryParams are the parameters to be used when making a request to start a new cursor
 // for a provided AQL query to retrieve results in batches.
+// FullCount provides the number of results the query would have without its last LIMIT in
+// the stats of the result, MaxRuntime aborts the query after the provided number of seconds and
+// MemoryLimit aborts it when it uses more than the provided number of bytes. FailOnWarning turns
+// warnings into errors and Cache allows the results to be served from the query results cache.
 type CursorQueryParams struct {
-	Query     string                 `json:"query"`
-	BindVars  map[string]interface{} `json:"bindVars"`
-	BatchSize int                    `json:"batchSize"`
-	Count     bool                   `json:"count"`
+	Query         string                 `json:"query"`
+	BindVars      map[string]interface{} `json:"bindVars"`
+	BatchSize     int                    `json:"batchSize"`
+	Count         bool                   `json:"count"`
+	FullCount     bool                   `json:"fullCount,omitempty"`
+	MaxRuntime    float64                `json:"maxRuntime,omitempty"`
+	MemoryLimit   int64                  `json:"memoryLimit,omitempty"`
+	FailOnWarning bool                   `json:"failOnWarning,omitempty"`
+	Cache         bool                   `json:"cache,omitempty"`
 }
 
 // CursorQueryResult provides the response result for cursor queries.
+// Count is only set when requested and Stats and Warnings are provided
+// with the first batch and updated with the last one.
 type CursorQueryResult struct {
 	Err        error
 	StatusCode int
@@ -122,16 +214,138 @@ type CursorQueryResult struct {
 	Documents  io.Reader
 	Cursor     string
 	HasMore    bool
+	Count      int
+	Cached     bool
+	Stats      *CursorQueryStats
+	Warnings   []*QueryWarning
+}
+
+// CursorIteratorOptions are the options used to control how a cursor iterator
+// retrieves the batches of a cursor.
+type CursorIteratorOptions struct {
+	// Prefetch is the amount of batches retrieved in the background and held ahead of the
+	// batch being processed, defaults to 0 which retrieves each batch only once the previous
+	// one has been processed.
+	Prefetch int
 }
 
+// CursorQueryStats provides the statistics of running a query, FullCount is only set when
+// requested and ExecutionTime is in seconds and PeakMemoryUsage in bytes.
+type CursorQueryStats struct {
+	WritesExecuted  int64   `json:"writesExecuted"`
+	WritesIgnored   int64   `json:"writesIgnored"`
+	ScannedFull     int64   `json:"scannedFull"`
+	ScannedIndex    int64   `json:"scannedIndex"`
+	Filtered        int64   `json:"filtered"`
+	FullCount       int64   `json:"fullCount"`
+	ExecutionTime   float64 `json:"executionTime"`
+	PeakMemoryUsage int64   `json:"peakMemoryUsage"`
+}
+
+// QueryWarning provides a warning raised while running or planning a query,
+// such as a division by zero or an invalid function argument.
+type QueryWarning struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ModifyingQuerySchemaVersion is the version of the request schema for the modification
+// query endpoints, it is sent with every modifying query so the service can tell
+// which fields to expect. Version 1 is the camelCase schema described by the json
+// tags of ModifyingQueryParams.
+const ModifyingQuerySchemaVersion = 1
+
 // ModifyingQueryParams are the parameters to be used when making a request
 // to the modification query endpoints to carry out INSERT, UPDATE or REMOVE operations
-// through AQL queries.
+// through AQL queries. Queries writing to several collections declare all of them in
+// WriteCollections, WriteCollection is kept for queries that write to a single collection.
+// ExclusiveCollections are locked for the query so no other writes can happen concurrently.
+//
+// WaitForSync waits for the changes to be synced to disk before responding, ReturnOld and
+// ReturnNew make the service provide the documents before and after the changes as the Old
+// and New of the DocumentsOpResult and IgnoreErrors carries on with the remaining operations when a single operation fails.
+// The client sets SchemaVersion to ModifyingQuerySchemaVersion when it's left unset.
 type ModifyingQueryParams struct {
-	WriteCollection string
-	ReadCollections []string
-	Query           string
-	BindVars        map[string]interface{}
+	SchemaVersion        int                    `json:"schemaVersion"`
+	WriteCollection      string                 `json:"writeCollection,omitempty"`
+	WriteCollections     []string               `json:"writeCollections,omitempty"`
+	ExclusiveCollections []string               `json:"exclusiveCollections,omitempty"`
+	ReadCollections      []string               `json:"readCollections,omitempty"`
+	Query                string                 `json:"query"`
+	BindVars             map[string]interface{} `json:"bindVars,omitempty"`
+	WaitForSync          bool                   `json:"waitForSync,omitempty"`
+	ReturnOld            bool                   `json:"returnOld,omitempty"`
+	ReturnNew            bool                   `json:"returnNew,omitempty"`
+	IgnoreErrors         bool                   `json:"ignoreErrors,omitempty"`
+}
+
+// TransactionParams are the parameters to be used when making a request to execute a transaction.
+// Either a sequence of AQL statements or the source of a JavaScript action function
+// should be provided, every collection the transaction reads from or writes to
+// must be declared up front.
+type TransactionParams struct {
+	ReadCollections      []string                `json:"readCollections"`
+	WriteCollections     []string                `json:"writeCollections"`
+	ExclusiveCollections []string                `json:"exclusiveCollections"`
+	Statements           []*TransactionStatement `json:"statements,omitempty"`
+	Action               string                  `json:"action,omitempty"`
+	Params               map[string]interface{}  `json:"params,omitempty"`
+	WaitForSync          bool                    `json:"waitForSync"`
+	LockTimeout          int                     `json:"lockTimeout,omitempty"`
+}
+
+// TransactionStatement provides a single AQL statement to be executed as part of a transaction,
+// the bind variables of a statement can't reference the results of previous statements.
+type TransactionStatement struct {
+	Query    string                 `json:"query"`
+	BindVars map[string]interface{} `json:"bindVars"`
+}
+
+// TransactionResult provides the response data for an attempted transaction.
+// Results holds the result of each statement in the order they were provided
+// and ActionResult holds the value returned by a JavaScript action.
+type TransactionResult struct {
+	Err          error
+	StatusCode   int
+	Message      string
+	Results      []*StatementResult
+	ActionResult io.Reader
+}
+
+// StatementResult provides the documents and events produced by a single
+// statement of a transaction.
+type StatementResult struct {
+	Documents     io.Reader
+	Events        io.Reader
+	DecodedEvents []*Event
+}
+
+// TransactionCollections declares the collections a stream transaction reads from and writes to,
+// exclusive collections are locked for the transaction so no other writes can happen concurrently.
+type TransactionCollections struct {
+	Read      []string `json:"read"`
+	Write     []string `json:"write"`
+	Exclusive []string `json:"exclusive"`
+}
+
+// TransactionOptions are the options used when beginning a stream transaction.
+// LockTimeout is how long to wait for the collection locks and IdleTimeout is how long
+// the transaction can go without any operations before the service aborts it, both are
+// in seconds and the service defaults are used when they are zero.
+type TransactionOptions struct {
+	WaitForSync bool `json:"waitForSync"`
+	LockTimeout int  `json:"lockTimeout,omitempty"`
+	IdleTimeout int  `json:"idleTimeout,omitempty"`
+}
+
+// TransactionStatusResult provides the response data for operations
+// that begin, commit or abort a stream transaction.
+type TransactionStatusResult struct {
+	Err        error
+	StatusCode int
+	Message    string
+	ID         string
+	Status     string
 }
 
 // ConnectionParams are the parameters used when invoking sessions for clients.